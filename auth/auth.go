@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"fmt"
+)
+
+// legacyAPITokenLength is the length of legacy API tokens, UAA refresh tokens being longer
+const legacyAPITokenLength = 20
+
+// LegacyTokenDeprecation : the warning printed whenever a legacy API token is used
+const LegacyTokenDeprecation = "The use of static Pivnet API tokens is deprecated and will be removed. Please see https://network.pivotal.io/docs/api#how-to-authenticate for details."
+
+// IsLegacyToken : whether the token is a legacy API token, which is used as is, rather than a UAA refresh token,
+// which is exchanged for an access token
+func IsLegacyToken(token string) bool {
+	return len(token) <= legacyAPITokenLength
+}
+
+// RequireRefreshToken : errors when the token is a legacy API token but UAA refresh tokens are required
+func RequireRefreshToken(token string, required bool) error {
+	if required && IsLegacyToken(token) {
+		return fmt.Errorf("api_token must be a UAA refresh token when %s is true, legacy API tokens are not accepted. Please see https://network.pivotal.io/docs/api#how-to-authenticate for details.", "require_refresh_token")
+	}
+	return nil
+}
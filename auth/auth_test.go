@@ -0,0 +1,43 @@
+package auth_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/auth"
+)
+
+const (
+	legacyToken  = "abcdefghij0123456789"
+	refreshToken = "some-uaa-refresh-token-longer-than-a-legacy-token"
+)
+
+var _ = Describe("Auth", func() {
+	Describe("IsLegacyToken", func() {
+		It("classifies tokens of up to 20 characters as legacy API tokens", func() {
+			Expect(auth.IsLegacyToken(legacyToken)).To(BeTrue())
+			Expect(auth.IsLegacyToken("short")).To(BeTrue())
+		})
+
+		It("classifies longer tokens as UAA refresh tokens", func() {
+			Expect(auth.IsLegacyToken(refreshToken)).To(BeFalse())
+		})
+	})
+
+	Describe("RequireRefreshToken", func() {
+		It("accepts any token when refresh tokens are not required", func() {
+			Expect(auth.RequireRefreshToken(legacyToken, false)).To(Succeed())
+			Expect(auth.RequireRefreshToken(refreshToken, false)).To(Succeed())
+		})
+
+		It("accepts refresh tokens when they are required", func() {
+			Expect(auth.RequireRefreshToken(refreshToken, true)).To(Succeed())
+		})
+
+		It("rejects legacy API tokens when refresh tokens are required", func() {
+			err := auth.RequireRefreshToken(legacyToken, true)
+			Expect(err).To(MatchError(ContainSubstring("api_token must be a UAA refresh token when require_refresh_token is true")))
+			Expect(err.Error()).NotTo(ContainSubstring(legacyToken))
+		})
+	})
+})
@@ -1,15 +1,22 @@
 package check
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/blang/semver"
 	"github.com/pivotal-cf/go-pivnet/v7"
 	"github.com/pivotal-cf/go-pivnet/v7/logger"
-	
+
 	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/dependents"
+	"github.com/shanman190/pivnet-product-stemcell-resource/multifilter"
+	"github.com/shanman190/pivnet-product-stemcell-resource/sorting"
+	"github.com/shanman190/pivnet-product-stemcell-resource/suggest"
 	"github.com/shanman190/pivnet-product-stemcell-resource/versions"
 )
 
@@ -31,6 +38,32 @@ type pivnetClient interface {
 	ReleasesForProductSlug(string) ([]pivnet.Release, error)
 	ReleaseDependencies(string, int) ([]pivnet.ReleaseDependency, error)
 	GetRelease(string, string) (pivnet.Release, error)
+	Products() ([]pivnet.Product, error)
+}
+
+const (
+	opsManagerSlug = "ops-manager"
+
+	maxSlugSuggestions = 3
+
+	endOfSupportDateLayout = "2006-01-02"
+)
+
+// stemcellSlugPattern matches the slugs of the known stemcell families, e.g. 'stemcells-ubuntu-jammy' or
+// 'stemcells-windows-server', which are detected when neither stemcell_slug nor dependency_slug are provided
+var stemcellSlugPattern = regexp.MustCompile(`^stemcells(-(ubuntu|windows|centos)(-[a-z0-9]+)+)?$`)
+
+//go:generate counterfeiter --fake-name FakeClock . clock
+type clock interface {
+	Now() time.Time
+}
+
+// SystemClock : clock returning the current system time
+type SystemClock struct{}
+
+// Now : returns the current system time
+func (SystemClock) Now() time.Time {
+	return time.Now()
 }
 
 // Command : Concourse Check command to search for new versions.
@@ -39,8 +72,8 @@ type Command struct {
 	binaryVersion string
 	filter        filter
 	pivnetClient  pivnetClient
-	sort		  sorter
-	logFilePath   string
+	sorter        *sorting.Sorter
+	clock         clock
 }
 
 // NewCheckCommand : Creates an instance of the check Command
@@ -50,218 +83,812 @@ func NewCheckCommand(
 	filter filter,
 	pivnetClient pivnetClient,
 	sort sorter,
-	logFilePath string,
+	clock clock,
 ) *Command {
 	return &Command{
 		logger:        logger,
 		binaryVersion: binaryVersion,
 		filter:        filter,
 		pivnetClient:  pivnetClient,
-		sort: 		   sort,
-		logFilePath:   logFilePath,
+		sorter:        sorting.NewSorter(logger, sort),
+		clock:         clock,
 	}
 }
 
-// Run : Execute the check command
-func (c *Command) Run(input concourse.CheckRequest) (concourse.CheckResponse, error) {
+// Run : Execute the check command, stopping with an InterruptedError once ctx ends
+func (c *Command) Run(ctx context.Context, input concourse.CheckRequest) (concourse.CheckResponse, error) {
+	command := *c
+	command.pivnetClient = contextClient{ctx: ctx, client: c.pivnetClient}
+
+	return command.run(input)
+}
+
+func (c *Command) run(input concourse.CheckRequest) (concourse.CheckResponse, error) {
 	c.logger.Info("Received input, starting Check CMD run")
 
-	err := c.removeExistingLogFiles()
+	releaseTypes := input.Source.ReleaseType
+
+	err := c.validateReleaseTypes(releaseTypes)
 	if err != nil {
 		return nil, err
 	}
 
-	releaseType := input.Source.ReleaseType
-
-	err = c.validateReleaseType(releaseType)
+	err = c.validateSlugs(input.Source)
 	if err != nil {
 		return nil, err
 	}
 
+	if input.Source.Mode == concourse.ModeReverse {
+		return c.runReverse(input)
+	}
+
 	productSlug := input.Source.ProductSlug
-	stemcellSlug := input.Source.StemcellSlug
+	dependencySlug := input.Source.TrackedDependencySlug()
 
 	c.logger.Info("Getting all product releases")
 	productReleases, err := c.pivnetClient.ReleasesForProductSlug(productSlug)
 	if err != nil {
 		return nil, err
 	}
+	c.debugReleases("Candidate product releases", productReleases)
 
-	if releaseType != "" {
-		c.logger.Info(fmt.Sprintf("Filtering all product releases by release type: '%s'", releaseType))
-		productReleases, err = c.filter.ReleasesByReleaseType(
-			productReleases,
-			pivnet.ReleaseType(releaseType),
-		)
+	f := multifilter.NewFilter(c.filter)
+
+	if len(releaseTypes) > 0 {
+		c.logger.Info(fmt.Sprintf("Filtering all product releases by release types: ['%s']", strings.Join(releaseTypes, "', '")))
+		productReleases, err = f.ReleasesByReleaseTypes(productReleases, releaseTypes)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	productVersion := input.Source.ProductVersion
-	if productVersion != "" {
-		c.logger.Info(fmt.Sprintf("Filtering all product releases by product version: '%s'", productVersion))
-		productReleases, err = c.filter.ReleasesByVersion(productReleases, productVersion)
+	productVersionPatterns := input.Source.ProductVersion
+	if len(productVersionPatterns) > 0 {
+		c.logger.Info(fmt.Sprintf("Filtering all product releases by product versions: ['%s']", strings.Join(productVersionPatterns, "', '")))
+		productReleases, err = f.ReleasesByVersions(productReleases, productVersionPatterns)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if input.Source.SortBy == concourse.SortBySemver {
-		c.logger.Info("Sorting all product releases by semver")
-		productReleases, err = c.sort.SortBySemver(productReleases)
+	if input.Source.ProductVersionConstraint != "" {
+		c.logger.Info(fmt.Sprintf("Filtering all product releases by product version constraint: '%s'", input.Source.ProductVersionConstraint))
+		constraint, err := versions.NewConstraint(input.Source.ProductVersionConstraint)
 		if err != nil {
 			return nil, err
 		}
-	} else if input.Source.SortBy == concourse.SortByLastUpdated {
-		c.logger.Info("Sorting all product releases by release date")
-		productReleases, err = c.sort.SortByLastUpdated(productReleases)
+		productReleases = constraint.Releases(productReleases)
+	}
+
+	productReleases = c.releasesByAvailabilityAndSupport("product", productReleases, input.Source)
+
+	productReleases, err = c.releasesByMinimumAge("product", productReleases, input.Source.MinReleaseAge.Product)
+	if err != nil {
+		return nil, err
+	}
+
+	productReleases, err = c.releasesWithoutExclusions("product", productReleases, input.Source.ExcludeProductVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	var opsManagerVersion *versions.Constraint
+	if input.Source.OpsManagerVersion != "" {
+		constraint, err := versions.NewConstraint(input.Source.OpsManagerVersion)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("provided opsman_version: '%s' is not a valid semver version or range: %s", input.Source.OpsManagerVersion, err)
 		}
+		opsManagerVersion = &constraint
+	}
+
+	productReleases, err = c.sorter.Sort(productSlug, productReleases, input.Source.SortBy)
+	if err != nil {
+		return nil, err
 	}
+	c.debugReleases("Filtered product releases", productReleases)
 
 	if len(productReleases) == 0 {
-		return concourse.CheckResponse{}, fmt.Errorf("cannot find specified product release")
+		return concourse.CheckResponse{}, ProductReleaseNotFoundError{ProductSlug: productSlug}
+	}
+
+	releaseDependencyCache := make(map[int][]pivnet.ReleaseDependency)
+
+	if input.Source.Track != "" {
+		return c.runTracked(input, productReleases, opsManagerVersion, releaseDependencyCache)
 	}
 
 	c.logger.Info("Gathering new product versions")
 
 	lastSeenProductVersion, _, _ := versions.SplitIntoVersionAndFingerprint(input.Version.ProductVersion)
-	newProductReleases, err := versions.SinceRelease(productReleases, lastSeenProductVersion)
+	newProductReleases, err := c.newProductReleases(productSlug, productReleases, lastSeenProductVersion, opsManagerVersion, releaseDependencyCache)
 	if err != nil {
-		// Untested because versions.Since cannot be forced to return an error.
 		return nil, err
 	}
 
-	c.logger.Info("Gathering new stemcell versions")
+	if len(newProductReleases) == 0 {
+		return concourse.CheckResponse{}, ProductReleaseNotFoundError{ProductSlug: productSlug}
+	}
+
+	if dependencySlug != "" {
+		c.logger.Info(fmt.Sprintf("Gathering new '%s' versions", dependencySlug))
+	} else {
+		c.logger.Info("Gathering new stemcell versions, detecting the stemcell slug of each product release")
+	}
+
+	lastSeenDependencyVersion, _, _ := versions.SplitIntoVersionAndFingerprint(input.Version.TrackedDependencyVersion())
 
-	productsToStemcells := make(map[string][]string)
-	stemcellCache := make(map[string]pivnet.Release)
+	productsToDependencies := make(map[string][]string)
+	productsToStemcellSlugs := make(map[string]string)
+	dependencyCache := make(map[string]pivnet.Release)
 	for _, productRelease := range newProductReleases {
-		c.logger.Info(fmt.Sprintf("Getting release dependencies for '%s/%s'", productSlug, productRelease.Version))
-		releaseDependencies, err := c.pivnetClient.ReleaseDependencies(productSlug, productRelease.ID)
+		productDependencySlug := dependencySlug
+		if productDependencySlug == "" {
+			productDependencySlug, err = c.detectStemcellSlug(productSlug, productRelease, releaseDependencyCache)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		productLastSeenDependencyVersion := lastSeenDependencyVersion
+		if dependencySlug == "" && productDependencySlug != input.Version.StemcellSlug {
+			// the last seen stemcell is of another family, so its version says nothing about this one
+			productLastSeenDependencyVersion = ""
+		}
+
+		fingerprintedDependencyVersions, err := c.newDependencyVersions(
+			input.Source,
+			productDependencySlug,
+			productRelease,
+			productLastSeenDependencyVersion,
+			releaseDependencyCache,
+			dependencyCache,
+		)
 		if err != nil {
 			return nil, err
 		}
 
-		if len(releaseDependencies) == 0 {
-			return nil, fmt.Errorf("cannot find specified dependencies for product release")
+		if fingerprintedDependencyVersions == nil {
+			continue
 		}
 
-		var stemcellVersions []string
-		for _, productReleaseDependency := range releaseDependencies {
-			if strings.Contains(productReleaseDependency.Release.Product.Slug, stemcellSlug) {
-				stemcellVersions = append(stemcellVersions, productReleaseDependency.Release.Version)
-			}
+		fingerprintedProductVersion, err := versions.CombineVersionAndFingerprint(productRelease.Version, productRelease.SoftwareFilesUpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		productsToDependencies[fingerprintedProductVersion] = fingerprintedDependencyVersions
+		if dependencySlug == "" {
+			productsToStemcellSlugs[fingerprintedProductVersion] = productDependencySlug
 		}
+	}
+
+	c.logger.Info(fmt.Sprintf("New versions: %v", productsToDependencies))
 
-		if len(stemcellVersions) == 0 {
-			return nil, fmt.Errorf("cannot find specified stemcells for product release")
+	var out concourse.CheckResponse
+	productVersions, err := releaseVersions(productReleases)
+	if err != nil {
+		return nil, err
+	}
+	reversedProductVersions, err := versions.Reverse(productVersions)
+	if err != nil {
+		// Untested because versions.Reverse cannot be forced to return an error.
+		return nil, err
+	}
+
+	for _, pv := range reversedProductVersions {
+		reversedDependencyVersions, err := versions.Reverse(productsToDependencies[pv])
+		if err != nil {
+			return nil, err
 		}
 
-		var stemcellReleases []pivnet.Release
-		for _, stemcellVersion := range stemcellVersions {
-			c.logger.Info(fmt.Sprintf("Getting release details for '%s/%s'", stemcellSlug, stemcellVersion))
-			stemcellRelease, ok := stemcellCache[stemcellVersion]
-			if !ok {
-				stemcellRelease, err = c.pivnetClient.GetRelease(stemcellSlug, stemcellVersion)
-				if err != nil {
-					return nil, err
-				}
+		for _, dv := range reversedDependencyVersions {
+			version := concourse.NewVersion(input.Source, pv, dv)
+			version.StemcellSlug = productsToStemcellSlugs[pv]
+			out = append(out, version)
+		}
+	}
 
-				stemcellCache[stemcellVersion] = stemcellRelease
-			}
+	if input.Source.SortBy == concourse.SortByStemcellFirst {
+		c.logger.Info("Ordering new versions by their stemcell version before their product version")
+		out = stemcellFirst(out)
+	}
+
+	c.logger.Info("Finishing check and returning output")
+
+	return out, nil
+}
 
-			stemcellReleases = append(stemcellReleases, stemcellRelease)
+// stemcellFirst orders the versions by their dependency version, oldest first, keeping the existing order of versions
+// with the same dependency version
+func stemcellFirst(out concourse.CheckResponse) concourse.CheckResponse {
+	dependencyVersions := make(map[string]semver.Version)
+	for _, version := range out {
+		dv, _, err := versions.SplitIntoVersionAndFingerprint(version.TrackedDependencyVersion())
+		if err != nil {
+			// releases without software files have no fingerprint
+			dv = version.TrackedDependencyVersion()
 		}
+		dependencyVersions[version.TrackedDependencyVersion()], _ = versions.ParseTolerant(dv)
+	}
+
+	sorted := make(concourse.CheckResponse, len(out))
+	copy(sorted, out)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return dependencyVersions[sorted[i].TrackedDependencyVersion()].LT(dependencyVersions[sorted[j].TrackedDependencyVersion()])
+	})
+	return sorted
+}
+
+// newDependencyVersions returns the fingerprinted versions of the dependency releases of the product release since the
+// last seen dependency version, newest first. It returns nil when none of the dependency releases are available.
+func (c *Command) newDependencyVersions(
+	source concourse.Source,
+	dependencySlug string,
+	productRelease pivnet.Release,
+	lastSeenDependencyVersion string,
+	releaseDependencyCache map[int][]pivnet.ReleaseDependency,
+	dependencyCache map[string]pivnet.Release,
+) ([]string, error) {
+	productSlug := source.ProductSlug
+
+	releaseDependencies, err := c.releaseDependencies(productSlug, productRelease, releaseDependencyCache)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(releaseDependencies) == 0 {
+		return nil, MissingDependencyError{
+			ProductSlug:      productSlug,
+			ProductVersion:   productRelease.Version,
+			ProductReleaseID: productRelease.ID,
+		}
+	}
 
-		if input.Source.SortBy == concourse.SortBySemver {
-			c.logger.Info("Sorting all stemcell releases by semver")
-			stemcellReleases, err = c.sort.SortBySemver(stemcellReleases)
+	var dependencyVersions []string
+	for _, productReleaseDependency := range releaseDependencies {
+		if strings.Contains(productReleaseDependency.Release.Product.Slug, dependencySlug) {
+			dependencyVersions = append(dependencyVersions, productReleaseDependency.Release.Version)
+		}
+	}
+
+	if len(dependencyVersions) == 0 {
+		return nil, MissingDependencyError{
+			ProductSlug:      productSlug,
+			ProductVersion:   productRelease.Version,
+			ProductReleaseID: productRelease.ID,
+			StemcellSlug:     dependencySlug,
+		}
+	}
+
+	var dependencyReleases []pivnet.Release
+	for _, dependencyVersion := range dependencyVersions {
+		c.logger.Info(fmt.Sprintf("Getting release details for '%s/%s'", dependencySlug, dependencyVersion))
+		// releases of different stemcell families may share a version
+		cacheKey := fmt.Sprintf("%s/%s", dependencySlug, dependencyVersion)
+		dependencyRelease, ok := dependencyCache[cacheKey]
+		if !ok {
+			dependencyRelease, err = c.pivnetClient.GetRelease(dependencySlug, dependencyVersion)
 			if err != nil {
 				return nil, err
 			}
-		} else if input.Source.SortBy == concourse.SortByLastUpdated {
-			c.logger.Info("Sorting all stemcell releases by release date")
-			stemcellReleases, err = c.sort.SortByLastUpdated(stemcellReleases)
+
+			dependencyCache[cacheKey] = dependencyRelease
+		}
+
+		dependencyReleases = append(dependencyReleases, dependencyRelease)
+	}
+	c.debugReleases(fmt.Sprintf("Candidate '%s' releases of '%s/%s'", dependencySlug, productSlug, productRelease.Version), dependencyReleases)
+
+	dependencyReleases = c.releasesByAvailabilityAndSupport(dependencySlug, dependencyReleases, source)
+
+	dependencyReleases, err = c.releasesByMinimumAge(dependencySlug, dependencyReleases, source.MinReleaseAge.Stemcell)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencyReleases, err = c.releasesWithoutExclusions(dependencySlug, dependencyReleases, source.ExcludeStemcellVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dependencyReleases) == 0 {
+		c.logger.Info(fmt.Sprintf("Skipping '%s/%s' as none of its '%s' releases are available", productSlug, productRelease.Version, dependencySlug))
+		return nil, nil
+	}
+
+	dependencyReleases, err = c.sorter.Sort(dependencySlug, dependencyReleases, source.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	c.debugReleases(fmt.Sprintf("Filtered '%s' releases of '%s/%s'", dependencySlug, productSlug, productRelease.Version), dependencyReleases)
+
+	dependencies, err := versions.SinceRelease(dependencyReleases, lastSeenDependencyVersion)
+	if err != nil {
+		// Untested because versions.Since cannot be forced to return an error.
+		return nil, err
+	}
+
+	fingerprintedDependencyVersions, err := releaseVersions(dependencies)
+	if err != nil {
+		// Untested because versions.CombineVersionAndFingerprint cannot be forced to return an error.
+		return nil, err
+	}
+
+	if len(fingerprintedDependencyVersions) == 0 {
+		return nil, DependencyReleaseNotFoundError{
+			ProductSlug:    productSlug,
+			ProductVersion: productRelease.Version,
+			StemcellSlug:   dependencySlug,
+		}
+	}
+
+	return fingerprintedDependencyVersions, nil
+}
+
+// runTracked emits the newest product release of each semver line paired with its newest dependency release. Every
+// line is emitted on each check, so a new patch on an older line is discovered independently of the newer lines.
+// Lines are ordered by when their newest release was published so the most recently published pair is the latest.
+// Release dates only have day precision, so of the lines published on the same day the last seen one comes first.
+func (c *Command) runTracked(
+	input concourse.CheckRequest,
+	productReleases []pivnet.Release,
+	opsManagerVersion *versions.Constraint,
+	releaseDependencyCache map[int][]pivnet.ReleaseDependency,
+) (concourse.CheckResponse, error) {
+	productSlug := input.Source.ProductSlug
+
+	c.logger.Info(fmt.Sprintf("Gathering the newest product release of each line to track: '%s'", input.Source.Track))
+
+	lastSeenProductVersion, _, _ := versions.SplitIntoVersionAndFingerprint(input.Version.ProductVersion)
+
+	var lineReleases []pivnet.Release
+	for _, line := range releasesPerLine(productReleases, input.Source.Track) {
+		newest, err := c.newProductReleases(productSlug, line, "", opsManagerVersion, releaseDependencyCache)
+		if err != nil {
+			return nil, err
+		}
+		lineReleases = append(lineReleases, newest...)
+	}
+
+	if len(lineReleases) == 0 {
+		return concourse.CheckResponse{}, ProductReleaseNotFoundError{ProductSlug: productSlug}
+	}
+
+	publishedAt := make(map[int]time.Time)
+	for _, release := range lineReleases {
+		t, err := sorting.PublishedAt(release)
+		if err != nil {
+			c.logger.Info(fmt.Sprintf("Cannot determine when '%s/%s' was published: %s", input.Source.ProductSlug, release.Version, err))
+		}
+		publishedAt[release.ID] = t
+	}
+
+	sort.SliceStable(lineReleases, func(i, j int) bool {
+		ti, tj := publishedAt[lineReleases[i].ID], publishedAt[lineReleases[j].ID]
+		if ti.Equal(tj) {
+			return lineReleases[i].Version == lastSeenProductVersion && lineReleases[j].Version != lastSeenProductVersion
+		}
+		return ti.Before(tj)
+	})
+
+	lastSeenDependencyVersion, _, _ := versions.SplitIntoVersionAndFingerprint(input.Version.TrackedDependencyVersion())
+
+	var out concourse.CheckResponse
+	dependencyCache := make(map[string]pivnet.Release)
+	for _, productRelease := range lineReleases {
+		dependencySlug := input.Source.TrackedDependencySlug()
+		if dependencySlug == "" {
+			var err error
+			dependencySlug, err = c.detectStemcellSlug(productSlug, productRelease, releaseDependencyCache)
 			if err != nil {
 				return nil, err
 			}
 		}
 
-		lastSeenStemcellVersion, _, _ := versions.SplitIntoVersionAndFingerprint(input.Version.StemcellVersion)
-		stemcells, err := versions.SinceRelease(stemcellReleases, lastSeenStemcellVersion)
-		if err != nil {
-			// Untested because versions.Since cannot be forced to return an error.
-			return nil, err
+		// only the line of the last seen version resumes from the last seen dependency version
+		productLastSeenDependencyVersion := ""
+		if productRelease.Version == lastSeenProductVersion &&
+			(input.Source.TrackedDependencySlug() != "" || dependencySlug == input.Version.StemcellSlug) {
+			productLastSeenDependencyVersion = lastSeenDependencyVersion
 		}
 
-		fingerprintedStemcellVersions, err := releaseVersions(stemcells)
+		fingerprintedDependencyVersions, err := c.newDependencyVersions(
+			input.Source,
+			dependencySlug,
+			productRelease,
+			productLastSeenDependencyVersion,
+			releaseDependencyCache,
+			dependencyCache,
+		)
 		if err != nil {
-			// Untested because versions.CombineVersionAndFingerprint cannot be forced to return an error.
-			return concourse.CheckResponse{}, err
+			return nil, err
 		}
 
-		if len(fingerprintedStemcellVersions) == 0 {
-			return concourse.CheckResponse{}, fmt.Errorf("cannot find specified stemcell release")
+		if fingerprintedDependencyVersions == nil {
+			continue
 		}
 
 		fingerprintedProductVersion, err := versions.CombineVersionAndFingerprint(productRelease.Version, productRelease.SoftwareFilesUpdatedAt)
 		if err != nil {
 			return nil, err
 		}
-		productsToStemcells[fingerprintedProductVersion] = fingerprintedStemcellVersions
+
+		version := concourse.NewVersion(input.Source, fingerprintedProductVersion, fingerprintedDependencyVersions[0])
+		if input.Source.TrackedDependencySlug() == "" {
+			version.StemcellSlug = dependencySlug
+		}
+		out = append(out, version)
 	}
 
-	c.logger.Info(fmt.Sprintf("New versions: %v", productsToStemcells))
+	c.logger.Info("Finishing check and returning output")
+
+	return out, nil
+}
+
+// releasesPerLine returns the releases of each line newest first by semver, in ascending order of line. Releases
+// whose versions cannot be parsed are not part of any line.
+func releasesPerLine(releases []pivnet.Release, track concourse.Track) [][]pivnet.Release {
+	type lineRelease struct {
+		release pivnet.Release
+		version semver.Version
+	}
+
+	type line struct {
+		key      semver.Version
+		releases []lineRelease
+	}
+
+	var lines []*line
+	linesByKey := make(map[string]*line)
+	for _, release := range releases {
+		v, err := versions.ParseTolerant(release.Version)
+		if err != nil {
+			continue
+		}
+
+		key := semver.Version{Major: v.Major}
+		if track == concourse.TrackPerMinor {
+			key.Minor = v.Minor
+		}
+
+		l, ok := linesByKey[key.String()]
+		if !ok {
+			l = &line{key: key}
+			linesByKey[key.String()] = l
+			lines = append(lines, l)
+		}
+		l.releases = append(l.releases, lineRelease{release: release, version: v})
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].key.LT(lines[j].key)
+	})
+
+	perLine := make([][]pivnet.Release, len(lines))
+	for i, l := range lines {
+		sort.SliceStable(l.releases, func(a, b int) bool {
+			return l.releases[a].version.GT(l.releases[b].version)
+		})
+
+		perLine[i] = make([]pivnet.Release, len(l.releases))
+		for j, r := range l.releases {
+			perLine[i][j] = r.release
+		}
+	}
+	return perLine
+}
+
+func (c *Command) runReverse(input concourse.CheckRequest) (concourse.CheckResponse, error) {
+	dependencySlug := input.Source.TrackedDependencySlug()
+
+	c.logger.Info(fmt.Sprintf("Getting all '%s' releases", dependencySlug))
+	dependencyReleases, err := c.pivnetClient.ReleasesForProductSlug(dependencySlug)
+	if err != nil {
+		return nil, err
+	}
+	c.debugReleases(fmt.Sprintf("Candidate '%s' releases", dependencySlug), dependencyReleases)
+
+	c.logger.Info(fmt.Sprintf("Gathering product releases which depend on '%s'", dependencySlug))
+	dependentReleases, err := dependents.NewFinder(c.logger, c.filter, c.pivnetClient).Find(input.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependedOnReleases []pivnet.Release
+	for _, dependencyRelease := range dependencyReleases {
+		if len(dependentReleases[dependencyRelease.Version]) > 0 {
+			dependedOnReleases = append(dependedOnReleases, dependencyRelease)
+		}
+	}
+
+	dependedOnReleases = c.releasesByAvailabilityAndSupport(dependencySlug, dependedOnReleases, input.Source)
+
+	dependedOnReleases, err = c.releasesByMinimumAge(dependencySlug, dependedOnReleases, input.Source.MinReleaseAge.Stemcell)
+	if err != nil {
+		return nil, err
+	}
+
+	dependedOnReleases, err = c.releasesWithoutExclusions(dependencySlug, dependedOnReleases, input.Source.ExcludeStemcellVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	dependedOnReleases, err = c.sorter.Sort(dependencySlug, dependedOnReleases, input.Source.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	c.debugReleases(fmt.Sprintf("Filtered '%s' releases", dependencySlug), dependedOnReleases)
+
+	if len(dependedOnReleases) == 0 {
+		return concourse.CheckResponse{}, DependentReleaseNotFoundError{
+			StemcellSlug: dependencySlug,
+			ProductSlugs: input.Source.TrackedProductSlugs(),
+		}
+	}
+
+	lastSeenDependencyVersion, _, _ := versions.SplitIntoVersionAndFingerprint(input.Version.TrackedDependencyVersion())
+	newDependencyReleases, err := versions.SinceRelease(dependedOnReleases, lastSeenDependencyVersion)
+	if err != nil {
+		// Untested because versions.Since cannot be forced to return an error.
+		return nil, err
+	}
 
 	var out concourse.CheckResponse
-	productVersions, err := releaseVersions(productReleases)
+	for i := len(newDependencyReleases) - 1; i >= 0; i-- {
+		dependencyRelease := newDependencyReleases[i]
+		c.logger.Info(fmt.Sprintf("'%s/%s' is a dependency of: %v", dependencySlug, dependencyRelease.Version, dependentReleases[dependencyRelease.Version]))
+
+		fingerprintedDependencyVersion, err := versions.CombineVersionAndFingerprint(dependencyRelease.Version, dependencyRelease.SoftwareFilesUpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, concourse.NewVersion(input.Source, "", fingerprintedDependencyVersion))
+	}
+
+	c.logger.Info("Finishing check and returning output")
+
+	return out, nil
+}
+
+func (c *Command) releasesByAvailabilityAndSupport(subject string, releases []pivnet.Release, source concourse.Source) []pivnet.Release {
+	if len(source.Availability) == 0 && !source.ExcludePastEOGS {
+		return releases
+	}
+
+	today := c.clock.Now().UTC().Truncate(24 * time.Hour)
+
+	var filteredReleases []pivnet.Release
+	for _, release := range releases {
+		if len(source.Availability) > 0 && !containsStringFold(source.Availability, release.Availability) {
+			c.logger.Info(fmt.Sprintf("Dropping '%s/%s' as its availability is: '%s'", subject, release.Version, release.Availability))
+			continue
+		}
+
+		if source.ExcludePastEOGS && release.EndOfSupportDate != "" {
+			endOfSupportDate, err := time.Parse(endOfSupportDateLayout, release.EndOfSupportDate)
+			if err != nil {
+				c.logger.Info(fmt.Sprintf("Ignoring unparseable end of general support date for '%s/%s': '%s'", subject, release.Version, release.EndOfSupportDate))
+			} else if endOfSupportDate.Before(today) {
+				c.logger.Info(fmt.Sprintf("Dropping '%s/%s' as its end of general support was: '%s'", subject, release.Version, release.EndOfSupportDate))
+				continue
+			}
+		}
+
+		filteredReleases = append(filteredReleases, release)
+	}
+
+	return filteredReleases
+}
+
+func (c *Command) releasesByMinimumAge(subject string, releases []pivnet.Release, minReleaseAge string) ([]pivnet.Release, error) {
+	minAge, err := concourse.ParseReleaseAge(minReleaseAge)
 	if err != nil {
 		return nil, err
 	}
-	reversedProductVersions, err := versions.Reverse(productVersions)
+
+	if minAge == 0 {
+		return releases, nil
+	}
+
+	c.logger.Info(fmt.Sprintf("Filtering all '%s' releases by minimum release age: '%s'", subject, minReleaseAge))
+	now := c.clock.Now()
+
+	var filteredReleases []pivnet.Release
+	for _, release := range releases {
+		publishedAt, err := sorting.PublishedAt(release)
+		if err != nil {
+			c.logger.Info(fmt.Sprintf("Dropping '%s/%s' as its release date cannot be determined: %s", subject, release.Version, err))
+			continue
+		}
+
+		if now.Sub(publishedAt) < minAge {
+			c.logger.Info(fmt.Sprintf("Dropping '%s/%s' as it was only released at: '%s'", subject, release.Version, publishedAt.Format(time.RFC3339)))
+			continue
+		}
+
+		filteredReleases = append(filteredReleases, release)
+	}
+
+	return filteredReleases, nil
+}
+
+func (c *Command) releasesWithoutExclusions(subject string, releases []pivnet.Release, excludedVersions []string) ([]pivnet.Release, error) {
+	if len(excludedVersions) == 0 {
+		return releases, nil
+	}
+
+	exclusions, err := versions.NewExclusions(excludedVersions)
 	if err != nil {
-		// Untested because versions.Reverse cannot be forced to return an error.
 		return nil, err
 	}
 
-	for _, pv := range reversedProductVersions {
-		reversedStemcellVersions, err := versions.Reverse(productsToStemcells[pv])
+	var filteredReleases []pivnet.Release
+	for _, release := range releases {
+		excluded := false
+		for _, exclusion := range exclusions {
+			if exclusion.Matches(release.Version) {
+				c.logger.Info(fmt.Sprintf("Excluding '%s/%s' as it matches: '%s'", subject, release.Version, exclusion))
+				excluded = true
+				break
+			}
+		}
+
+		if !excluded {
+			filteredReleases = append(filteredReleases, release)
+		}
+	}
+
+	return filteredReleases, nil
+}
+
+// detectStemcellSlug returns the slug of the single stemcell family the product release depends on, for sources which
+// provide neither a stemcell_slug nor a dependency_slug.
+func (c *Command) detectStemcellSlug(productSlug string, productRelease pivnet.Release, cache map[int][]pivnet.ReleaseDependency) (string, error) {
+	releaseDependencies, err := c.releaseDependencies(productSlug, productRelease, cache)
+	if err != nil {
+		return "", err
+	}
+
+	var stemcellSlugs []string
+	for _, releaseDependency := range releaseDependencies {
+		slug := releaseDependency.Release.Product.Slug
+		if stemcellSlugPattern.MatchString(slug) && !containsString(stemcellSlugs, slug) {
+			stemcellSlugs = append(stemcellSlugs, slug)
+		}
+	}
+	sort.Strings(stemcellSlugs)
+
+	if len(stemcellSlugs) != 1 {
+		return "", StemcellDetectionError{
+			ProductSlug:    productSlug,
+			ProductVersion: productRelease.Version,
+			StemcellSlugs:  stemcellSlugs,
+		}
+	}
+
+	c.logger.Info(fmt.Sprintf("Detected stemcell slug '%s' for '%s/%s'", stemcellSlugs[0], productSlug, productRelease.Version))
+	return stemcellSlugs[0], nil
+}
+
+func (c *Command) releaseDependencies(productSlug string, productRelease pivnet.Release, cache map[int][]pivnet.ReleaseDependency) ([]pivnet.ReleaseDependency, error) {
+	releaseDependencies, ok := cache[productRelease.ID]
+	if ok {
+		return releaseDependencies, nil
+	}
+
+	c.logger.Info(fmt.Sprintf("Getting release dependencies for '%s/%s'", productSlug, productRelease.Version))
+	releaseDependencies, err := c.pivnetClient.ReleaseDependencies(productSlug, productRelease.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cache[productRelease.ID] = releaseDependencies
+	return releaseDependencies, nil
+}
+
+// newProductReleases returns the product releases, newest first, up to and including the last seen version, or only the
+// newest of them when the last seen version is not one of them. Releases which do not support the Ops Manager version
+// are dropped, looking up the dependencies of only those releases which could be returned.
+func (c *Command) newProductReleases(
+	productSlug string,
+	productReleases []pivnet.Release,
+	lastSeenVersion string,
+	opsManagerVersion *versions.Constraint,
+	cache map[int][]pivnet.ReleaseDependency,
+) ([]pivnet.Release, error) {
+	onlyNewest := true
+	for _, productRelease := range productReleases {
+		if productRelease.Version == lastSeenVersion {
+			onlyNewest = false
+			break
+		}
+	}
+
+	if opsManagerVersion != nil {
+		c.logger.Info(fmt.Sprintf("Filtering new product releases by compatible Ops Manager version: '%s'", opsManagerVersion))
+	}
+
+	var newReleases []pivnet.Release
+	for _, productRelease := range productReleases {
+		supported, err := c.supportsOpsManagerVersion(productSlug, productRelease, opsManagerVersion, cache)
 		if err != nil {
 			return nil, err
 		}
 
-		for _, sv := range reversedStemcellVersions {
-			out = append(out, concourse.Version{ProductVersion: pv, StemcellVersion: sv})
+		if supported {
+			newReleases = append(newReleases, productRelease)
+			if onlyNewest {
+				break
+			}
+		} else {
+			c.logger.Info(fmt.Sprintf("Dropping '%s/%s' as it does not support Ops Manager version: '%s'", productSlug, productRelease.Version, opsManagerVersion))
+		}
+
+		if productRelease.Version == lastSeenVersion {
+			break
 		}
 	}
 
-	c.logger.Info("Finishing check and returning output")
+	return newReleases, nil
+}
 
-	return out, nil
+// supportsOpsManagerVersion reports whether the product release depends on an Ops Manager release within the Ops
+// Manager version, which every release does when no Ops Manager version is provided
+func (c *Command) supportsOpsManagerVersion(productSlug string, productRelease pivnet.Release, opsManagerVersion *versions.Constraint, cache map[int][]pivnet.ReleaseDependency) (bool, error) {
+	if opsManagerVersion == nil {
+		return true, nil
+	}
+
+	releaseDependencies, err := c.releaseDependencies(productSlug, productRelease, cache)
+	if err != nil {
+		return false, err
+	}
+
+	for _, releaseDependency := range releaseDependencies {
+		if releaseDependency.Release.Product.Slug == opsManagerSlug && opsManagerVersion.Matches(releaseDependency.Release.Version) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
-func (c *Command) removeExistingLogFiles() error {
-	logDir := filepath.Dir(c.logFilePath)
-	existingLogFiles, err := filepath.Glob(filepath.Join(logDir, "*.log*"))
+// validateSlugs checks that the product and dependency slugs exist on Pivotal Network, suggesting the closest slugs
+// when one does not so that a typo is not reported as a missing release
+func (c *Command) validateSlugs(source concourse.Source) error {
+	productSlugs := []string{source.ProductSlug}
+	if source.Mode == concourse.ModeReverse {
+		productSlugs = source.TrackedProductSlugs()
+	}
+
+	slugs := map[string][]string{
+		"product_slug": productSlugs,
+	}
+	if source.DependencySlug != "" {
+		slugs["dependency_slug"] = []string{source.DependencySlug}
+	} else if source.StemcellSlug != "" {
+		slugs["stemcell_slug"] = []string{source.StemcellSlug}
+	}
+
+	c.logger.Info("Validating product slugs")
+	products, err := c.pivnetClient.Products()
 	if err != nil {
-		// This is untested because the only error returned by filepath.Glob is a
-		// malformed glob, and this glob is hard-coded to be correct.
 		return err
 	}
 
-	c.logger.Info(fmt.Sprintf("Located logfiles: %v", existingLogFiles))
+	knownSlugs := make([]string, len(products))
+	for i, product := range products {
+		knownSlugs[i] = product.Slug
+	}
 
-	for _, f := range existingLogFiles {
-		if filepath.Base(f) != filepath.Base(c.logFilePath) {
-			c.logger.Info(fmt.Sprintf("Removing existing log file: %s", f))
-			err := os.Remove(f)
-			if err != nil {
-				// This is untested because it is too hard to force os.Remove to return
-				// an error.
-				return err
+	for _, key := range []string{"product_slug", "stemcell_slug", "dependency_slug"} {
+		for _, slug := range slugs[key] {
+			if containsString(knownSlugs, slug) {
+				continue
+			}
+
+			return SlugNotFoundError{
+				Key:         key,
+				Slug:        slug,
+				Suggestions: suggest.Closest(slug, knownSlugs, maxSlugSuggestions),
 			}
 		}
 	}
@@ -269,25 +896,25 @@ func (c *Command) removeExistingLogFiles() error {
 	return nil
 }
 
-func (c *Command) validateReleaseType(releaseType string) error {
-	c.logger.Info(fmt.Sprintf("Validating release type: '%s'", releaseType))
-	releaseTypes, err := c.pivnetClient.ReleaseTypes()
+func (c *Command) validateReleaseTypes(releaseTypes []string) error {
+	c.logger.Info(fmt.Sprintf("Validating release types: ['%s']", strings.Join(releaseTypes, "', '")))
+	validReleaseTypes, err := c.pivnetClient.ReleaseTypes()
 	if err != nil {
 		return err
 	}
 
-	releaseTypesAsStrings := make([]string, len(releaseTypes))
-	for i, r := range releaseTypes {
+	releaseTypesAsStrings := make([]string, len(validReleaseTypes))
+	for i, r := range validReleaseTypes {
 		releaseTypesAsStrings[i] = string(r)
 	}
 
-	if releaseType != "" && !containsString(releaseTypesAsStrings, releaseType) {
-		releaseTypesPrintable := fmt.Sprintf("['%s']", strings.Join(releaseTypesAsStrings, "', '"))
-		return fmt.Errorf(
-			"provided release type: '%s' must be one of: %s",
-			releaseType,
-			releaseTypesPrintable,
-		)
+	for _, releaseType := range releaseTypes {
+		if !containsString(releaseTypesAsStrings, releaseType) {
+			return ReleaseTypeError{
+				ReleaseType:  releaseType,
+				ReleaseTypes: releaseTypesAsStrings,
+			}
+		}
 	}
 
 	return nil
@@ -302,6 +929,32 @@ func containsString(strings []string, str string) bool {
 	return false
 }
 
+func containsStringFold(values []string, str string) bool {
+	for _, v := range values {
+		if strings.EqualFold(str, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// debugReleases logs the details of the releases being considered at the debug level
+func (c *Command) debugReleases(message string, releases []pivnet.Release) {
+	details := make([]string, len(releases))
+	for i, r := range releases {
+		details[i] = fmt.Sprintf(
+			"'%s' (ID %d, %s, released %s, availability %s, files updated %s)",
+			r.Version,
+			r.ID,
+			r.ReleaseType,
+			r.ReleaseDate,
+			r.Availability,
+			r.SoftwareFilesUpdatedAt,
+		)
+	}
+	c.logger.Debug(fmt.Sprintf("%s: [%s]", message, strings.Join(details, ", ")))
+}
+
 func releaseVersions(releases []pivnet.Release) ([]string, error) {
 	releaseVersions := make([]string, len(releases))
 
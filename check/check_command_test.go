@@ -1,16 +1,17 @@
 package check_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"log"
-	"os"
-	"path/filepath"
+	"time"
 
 	"github.com/shanman190/pivnet-product-stemcell-resource/check"
 	"github.com/shanman190/pivnet-product-stemcell-resource/check/checkfakes"
 	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/interrupt"
 
 	"github.com/pivotal-cf/go-pivnet/v7"
 	"github.com/pivotal-cf/go-pivnet/v7/logger"
@@ -19,14 +20,17 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("Check", func() {
 	var (
 		fakeLogger       logger.Logger
+		logBuffer        *gbytes.Buffer
 		fakeFilter       *checkfakes.FakeFilter
 		fakePivnetClient *checkfakes.FakePivnetClient
 		fakeSorter       *checkfakes.FakeSorter
+		fakeClock        *checkfakes.FakeClock
 
 		checkRequest concourse.CheckRequest
 		checkCommand *check.Command
@@ -52,18 +56,23 @@ var _ = Describe("Check", func() {
 
 		stemcellReleases    []pivnet.Release
 		stemcellReleasesErr error
-		
-		tempDir     string
-		logFilePath string
 	)
 
 	BeforeEach(func() {
-		logger := log.New(GinkgoWriter, "", log.LstdFlags)
+		logBuffer = gbytes.NewBuffer()
+		logger := log.New(io.MultiWriter(GinkgoWriter, logBuffer), "", log.LstdFlags)
 		fakeLogger = logshim.NewLogShim(logger, logger, true)
 
 		fakeFilter = &checkfakes.FakeFilter{}
 		fakePivnetClient = &checkfakes.FakePivnetClient{}
 		fakeSorter = &checkfakes.FakeSorter{}
+		fakeClock = &checkfakes.FakeClock{}
+		fakeClock.NowReturns(time.Date(2023, time.June, 15, 12, 0, 0, 0, time.UTC))
+		fakePivnetClient.ProductsReturns([]pivnet.Product{
+			{ID: 1, Slug: "some product"},
+			{ID: 2, Slug: "some stemcell"},
+			{ID: 3, Slug: "ops-manager"},
+		}, nil)
 
 		productSlug = "some product"
 		stemcellSlug = "some stemcell"
@@ -182,14 +191,6 @@ var _ = Describe("Check", func() {
 				StemcellSlug: stemcellSlug,
 			},
 		}
-
-		var err error
-		tempDir, err = ioutil.TempDir("", "")
-		Expect(err).NotTo(HaveOccurred())
-
-		logFilePath = filepath.Join(tempDir, "pivnet-resource-check.log1234")
-		err = ioutil.WriteFile(logFilePath, []byte("initial log content"), os.ModePerm)
-		Expect(err).NotTo(HaveOccurred())
 	})
 
 	JustBeforeEach(func() {
@@ -207,15 +208,10 @@ var _ = Describe("Check", func() {
 			fakeFilter,
 			fakePivnetClient,
 			fakeSorter,
-			logFilePath,
+			fakeClock,
 		)
 	})
 
-	AfterEach(func() {
-		err := os.RemoveAll(tempDir)
-		Expect(err).NotTo(HaveOccurred())
-	})
-
 	Context("when no version data is provided", func() {
 		BeforeEach(func() {
 			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[0]}, allReleaseDependenciesErr)
@@ -227,7 +223,7 @@ var _ = Describe("Check", func() {
 		})
 
 		It("returns the most recent version without error", func() {
-			response, err := checkCommand.Run(checkRequest)
+			response, err := checkCommand.Run(context.Background(), checkRequest)
 			Expect(err).NotTo(HaveOccurred())
 
 			expectedProductVersionWithFingerprint := productVersionsWithFingerprints[0]
@@ -238,50 +234,390 @@ var _ = Describe("Check", func() {
 			Expect(response[0].StemcellVersion).To(Equal(expectedStemcellVersionWithFingerprint))
 		})
 
-		Context("when log files already exist", func() {
-			var (
-				otherFilePath1 string
-				otherFilePath2 string
-			)
+		It("logs the details of the releases being considered", func() {
+			_, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logBuffer).To(gbytes.Say(`Candidate product releases: \['1\.2\.3' \(ID 1, `))
+			Expect(logBuffer).To(gbytes.Say(`Filtered product releases: \['1\.2\.3' \(ID 1, `))
+			Expect(logBuffer).To(gbytes.Say(`Candidate 'some stemcell' releases of 'some product/1\.2\.3': \['100\.21' \(ID 11, `))
+			Expect(logBuffer).To(gbytes.Say(`Filtered 'some stemcell' releases of 'some product/1\.2\.3': \['100\.21'`))
+		})
+	})
+
+	Context("when a dependency slug is provided instead of a stemcell slug", func() {
+		BeforeEach(func() {
+			checkRequest.Source.StemcellSlug = ""
+			checkRequest.Source.DependencySlug = "some stemcell"
+
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[0]}, allReleaseDependenciesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(0, stemcellReleases[0], stemcellReleasesErr)
+		})
+
+		It("returns the most recent version keyed as a dependency version", func() {
+			response, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].ProductVersion).To(Equal(productVersionsWithFingerprints[0]))
+			Expect(response[0].DependencyVersion).To(Equal(stemcellVersionsWithFingerprints[0]))
+			Expect(response[0].StemcellVersion).To(BeEmpty())
+
+			slug, _ := fakePivnetClient.GetReleaseArgsForCall(0)
+			Expect(slug).To(Equal("some stemcell"))
+		})
+	})
+
+	Context("when the product release has no dependency on the tracked slug", func() {
+		BeforeEach(func() {
+			checkRequest.Source.StemcellSlug = ""
+			checkRequest.Source.DependencySlug = "ops-manager"
+
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[0]}, allReleaseDependenciesErr)
+		})
+
+		It("returns an error naming the slug", func() {
+			_, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("cannot find specified 'ops-manager' dependencies for product release"))
+
+			var missingDependencyErr check.MissingDependencyError
+			Expect(errors.As(err, &missingDependencyErr)).To(BeTrue())
+			Expect(missingDependencyErr.ProductSlug).To(Equal(productSlug))
+			Expect(missingDependencyErr.StemcellSlug).To(Equal("ops-manager"))
+			Expect(errors.Is(err, check.ErrNotFound)).To(BeTrue())
+		})
+	})
+
+	Context("when an Ops Manager version is specified", func() {
+		var (
+			opsManagerDependency = func(version string) pivnet.ReleaseDependency {
+				return pivnet.ReleaseDependency{
+					Release: pivnet.DependentRelease{
+						Version: version,
+						Product: pivnet.Product{
+							Slug: "ops-manager",
+						},
+					},
+				}
+			}
+		)
+
+		BeforeEach(func() {
+			checkRequest.Source.OpsManagerVersion = ">=2.10.0 <2.11.0"
+
+			checkRequest.Version = concourse.Version{
+				ProductVersion:  productVersionsWithFingerprints[2], // 1.2.4#time3
+				StemcellVersion: stemcellVersionsWithFingerprints[2], // 150.64#time3
+			}
+
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[0], opsManagerDependency("2.10.3")}, allReleaseDependenciesErr)
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, []pivnet.ReleaseDependency{allReleaseDependencies[1], opsManagerDependency("2.11.0")}, allReleaseDependenciesErr)
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(2, []pivnet.ReleaseDependency{allReleaseDependencies[2], opsManagerDependency("2.9.8"), opsManagerDependency("2.10")}, allReleaseDependenciesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(0, stemcellReleases[0], stemcellReleasesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(1, stemcellReleases[2], stemcellReleasesErr)
+		})
 
+		It("returns only product releases with a compatible Ops Manager dependency", func() {
+			response, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response).To(HaveLen(2))
+			Expect(response[0].ProductVersion).To(Equal(productVersionsWithFingerprints[2]))
+			Expect(response[0].StemcellVersion).To(Equal(stemcellVersionsWithFingerprints[2]))
+			Expect(response[1].ProductVersion).To(Equal(productVersionsWithFingerprints[0]))
+			Expect(response[1].StemcellVersion).To(Equal(stemcellVersionsWithFingerprints[0]))
+		})
+
+		It("only gets the release dependencies once per product release", func() {
+			_, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakePivnetClient.ReleaseDependenciesCallCount()).To(Equal(3))
+		})
+
+		Context("when the last seen product release is not the oldest", func() {
 			BeforeEach(func() {
-				otherFilePath1 = filepath.Join(tempDir, "pivnet-resource-check.log1")
-				err := ioutil.WriteFile(otherFilePath1, []byte("initial log content"), os.ModePerm)
-				Expect(err).NotTo(HaveOccurred())
+				checkRequest.Version = concourse.Version{
+					ProductVersion:  productVersionsWithFingerprints[1], // 2.3.4#time2
+					StemcellVersion: stemcellVersionsWithFingerprints[0], // 100.21#time1
+				}
+			})
 
-				otherFilePath2 = filepath.Join(tempDir, "pivnet-resource-check.log2")
-				err = ioutil.WriteFile(otherFilePath2, []byte("initial log content"), os.ModePerm)
+			It("only gets the release dependencies of the product releases since the last seen one", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response).To(Equal(concourse.CheckResponse{
+					{ProductVersion: productVersionsWithFingerprints[0], StemcellVersion: stemcellVersionsWithFingerprints[0]},
+				}))
+
+				Expect(fakePivnetClient.ReleaseDependenciesCallCount()).To(Equal(2))
+			})
+		})
+
+		Context("when no version is provided", func() {
+			BeforeEach(func() {
+				checkRequest.Version = concourse.Version{}
 			})
 
-			It("removes the other log files", func() {
-				_, err := checkCommand.Run(checkRequest)
+			It("only gets the release dependencies of the newest product release supporting the Ops Manager version", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).NotTo(HaveOccurred())
 
-				_, err = os.Stat(otherFilePath1)
+				Expect(response).To(Equal(concourse.CheckResponse{
+					{ProductVersion: productVersionsWithFingerprints[0], StemcellVersion: stemcellVersionsWithFingerprints[0]},
+				}))
+
+				Expect(fakePivnetClient.ReleaseDependenciesCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when no product release supports the Ops Manager version", func() {
+			BeforeEach(func() {
+				checkRequest.Source.OpsManagerVersion = "3.0.0"
+			})
+
+			It("returns an error", func() {
+				_, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).To(HaveOccurred())
-				Expect(os.IsNotExist(err)).To(BeTrue())
 
-				_, err = os.Stat(otherFilePath2)
+				Expect(err.Error()).To(ContainSubstring("cannot find specified product release"))
+			})
+		})
+
+		Context("when the Ops Manager version is invalid", func() {
+			BeforeEach(func() {
+				checkRequest.Source.OpsManagerVersion = "not a version"
+			})
+
+			It("returns an error", func() {
+				_, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).To(HaveOccurred())
-				Expect(os.IsNotExist(err)).To(BeTrue())
 
-				_, err = os.Stat(logFilePath)
+				Expect(err.Error()).To(ContainSubstring("opsman_version"))
+			})
+		})
+
+		Context("when getting release dependencies returns an error", func() {
+			BeforeEach(func() {
+				fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, nil, fmt.Errorf("some dependencies error"))
+			})
+
+			It("returns the error", func() {
+				_, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("some dependencies error"))
+			})
+		})
+	})
+
+	Context("when in reverse mode", func() {
+		BeforeEach(func() {
+			checkRequest.Source.Mode = concourse.ModeReverse
+
+			fakePivnetClient.ReleasesForProductSlugReturnsOnCall(0, stemcellReleases, nil)
+			fakePivnetClient.ReleasesForProductSlugReturnsOnCall(1, productReleases, nil)
+
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[0]}, allReleaseDependenciesErr)
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, []pivnet.ReleaseDependency{allReleaseDependencies[0]}, allReleaseDependenciesErr)
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(2, []pivnet.ReleaseDependency{allReleaseDependencies[2]}, allReleaseDependenciesErr)
+		})
+
+		It("returns the most recent stemcell version with dependent product releases", func() {
+			response, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakePivnetClient.ReleasesForProductSlugArgsForCall(0)).To(Equal(stemcellSlug))
+			Expect(fakePivnetClient.ReleasesForProductSlugArgsForCall(1)).To(Equal(productSlug))
+
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].ProductVersion).To(BeEmpty())
+			Expect(response[0].StemcellVersion).To(Equal(stemcellVersionsWithFingerprints[0]))
+		})
+
+		Context("when a stemcell version is provided", func() {
+			BeforeEach(func() {
+				checkRequest.Version = concourse.Version{
+					StemcellVersion: stemcellVersionsWithFingerprints[2], // 150.64#time3
+				}
+			})
+
+			It("returns the new stemcell versions which have dependent product releases, skipping those without", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response).To(HaveLen(2))
+				Expect(response[0].StemcellVersion).To(Equal(stemcellVersionsWithFingerprints[2]))
+				Expect(response[1].StemcellVersion).To(Equal(stemcellVersionsWithFingerprints[0]))
+			})
+
+			It("returns the last seen stemcell version unchanged so that it is not recorded again", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response[0]).To(Equal(checkRequest.Version))
+			})
+		})
+
+		Context("when no stemcell release has dependent product releases", func() {
+			BeforeEach(func() {
+				fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{}, allReleaseDependenciesErr)
+				fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, []pivnet.ReleaseDependency{}, allReleaseDependenciesErr)
+				fakePivnetClient.ReleaseDependenciesReturnsOnCall(2, []pivnet.ReleaseDependency{}, allReleaseDependenciesErr)
+			})
+
+			It("returns an error", func() {
+				_, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("cannot find specified 'some stemcell' release with dependent product releases"))
+			})
+		})
+
+		Context("when getting stemcell releases returns an error", func() {
+			BeforeEach(func() {
+				fakePivnetClient.ReleasesForProductSlugReturnsOnCall(0, nil, fmt.Errorf("some stemcell releases error"))
+			})
+
+			It("returns the error", func() {
+				_, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("some stemcell releases error"))
+			})
+		})
+	})
+
+	Context("when filtering by availability and end of general support", func() {
+		BeforeEach(func() {
+			checkRequest.Source.Availability = []string{"All Users"}
+			checkRequest.Source.ExcludePastEOGS = true
+
+			checkRequest.Version = concourse.Version{
+				ProductVersion:  productVersionsWithFingerprints[2], // 1.2.4#time3
+				StemcellVersion: stemcellVersionsWithFingerprints[2], // 150.64#time3
+			}
+
+			productReleases[0].Availability = "All Users"
+			productReleases[0].EndOfSupportDate = "2999-12-31"
+			productReleases[1].Availability = "Admins Only"
+			productReleases[2].Availability = "all users"
+
+			stemcellReleases[0].Availability = "All Users"
+			stemcellReleases[2].Availability = "All Users"
+			stemcellReleases[2].EndOfSupportDate = "2000-01-01"
+
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[0]}, allReleaseDependenciesErr)
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, []pivnet.ReleaseDependency{allReleaseDependencies[2]}, allReleaseDependenciesErr)
+		})
+
+		JustBeforeEach(func() {
+			fakePivnetClient.GetReleaseReturnsOnCall(0, stemcellReleases[0], stemcellReleasesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(1, stemcellReleases[2], stemcellReleasesErr)
+		})
+
+		It("returns only pairs where both releases are available and supported", func() {
+			response, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakePivnetClient.ReleaseDependenciesCallCount()).To(Equal(2))
+
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].ProductVersion).To(Equal(productVersionsWithFingerprints[0]))
+			Expect(response[0].StemcellVersion).To(Equal(stemcellVersionsWithFingerprints[0]))
+		})
+	})
+
+	Context("when a minimum release age is specified", func() {
+		BeforeEach(func() {
+			checkRequest.Source.MinReleaseAge = concourse.MinReleaseAge{
+				Product:  "3d",
+				Stemcell: "48h",
+			}
+
+			checkRequest.Version = concourse.Version{
+				ProductVersion:  productVersionsWithFingerprints[2], // 1.2.4#time3
+				StemcellVersion: stemcellVersionsWithFingerprints[2], // 150.64#time3
+			}
+
+			productReleases[0].ReleaseDate = "2023-06-14"
+			productReleases[1].ReleaseDate = "2023-06-01"
+			productReleases[2].UpdatedAt = "2023-06-01T10:00:00Z"
+
+			stemcellReleases[1].ReleaseDate = "2023-06-14"
+			stemcellReleases[2].ReleaseDate = "2023-06-13"
+
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[1]}, allReleaseDependenciesErr)
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, []pivnet.ReleaseDependency{allReleaseDependencies[2]}, allReleaseDependenciesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(0, stemcellReleases[1], stemcellReleasesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(1, stemcellReleases[2], stemcellReleasesErr)
+		})
+
+		It("returns only pairs where both releases have aged past their minimum age", func() {
+			response, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].ProductVersion).To(Equal(productVersionsWithFingerprints[2]))
+			Expect(response[0].StemcellVersion).To(Equal(stemcellVersionsWithFingerprints[2]))
+		})
+
+		Context("when the minimum release age is invalid", func() {
+			BeforeEach(func() {
+				checkRequest.Source.MinReleaseAge.Product = "three days"
+			})
+
+			It("returns an error", func() {
+				_, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("invalid release age: 'three days'"))
 			})
 		})
 	})
 
+	Context("when product and stemcell versions are excluded", func() {
+		BeforeEach(func() {
+			checkRequest.Source.ExcludeProductVersions = []string{"2.3.4"}
+			checkRequest.Source.ExcludeStemcellVersions = []string{">=150.0.0 <151.0.0"}
+
+			checkRequest.Version = concourse.Version{
+				ProductVersion:  productVersionsWithFingerprints[2], // 1.2.4#time3
+				StemcellVersion: stemcellVersionsWithFingerprints[2], // 150.64#time3
+			}
+
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[0]}, allReleaseDependenciesErr)
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, []pivnet.ReleaseDependency{allReleaseDependencies[0], allReleaseDependencies[2]}, allReleaseDependenciesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(0, stemcellReleases[0], stemcellReleasesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(1, stemcellReleases[2], stemcellReleasesErr)
+		})
+
+		It("does not return the excluded versions", func() {
+			response, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response).To(HaveLen(2))
+			Expect(response[0].ProductVersion).To(Equal(productVersionsWithFingerprints[2]))
+			Expect(response[0].StemcellVersion).To(Equal(stemcellVersionsWithFingerprints[0]))
+			Expect(response[1].ProductVersion).To(Equal(productVersionsWithFingerprints[0]))
+			Expect(response[1].StemcellVersion).To(Equal(stemcellVersionsWithFingerprints[0]))
+		})
+	})
+
 	Context("when no releases are returned", func() {
 		BeforeEach(func() {
 			productReleases = []pivnet.Release{}
 		})
 
 		It("returns an error", func() {
-			_, err := checkCommand.Run(checkRequest)
+			_, err := checkCommand.Run(context.Background(), checkRequest)
 			Expect(err).To(HaveOccurred())
 
 			Expect(err.Error()).To(ContainSubstring("cannot find specified product release"))
+			Expect(err).To(MatchError(check.ProductReleaseNotFoundError{ProductSlug: productSlug}))
 		})
 	})
 
@@ -291,10 +627,16 @@ var _ = Describe("Check", func() {
 		})
 
 		It("returns an error", func() {
-			_, err := checkCommand.Run(checkRequest)
+			_, err := checkCommand.Run(context.Background(), checkRequest)
 			Expect(err).To(HaveOccurred())
 
 			Expect(err.Error()).To(ContainSubstring("cannot find specified dependencies for product release"))
+
+			var missingDependencyErr check.MissingDependencyError
+			Expect(errors.As(err, &missingDependencyErr)).To(BeTrue())
+			Expect(missingDependencyErr.ProductSlug).To(Equal(productSlug))
+			Expect(missingDependencyErr.ProductReleaseID).NotTo(BeZero())
+			Expect(missingDependencyErr.StemcellSlug).To(BeEmpty())
 		})
 	})
 
@@ -304,26 +646,125 @@ var _ = Describe("Check", func() {
 		})
 
 		It("returns an error", func() {
-			_, err := checkCommand.Run(checkRequest)
+			_, err := checkCommand.Run(context.Background(), checkRequest)
 			Expect(err).To(HaveOccurred())
 
 			Expect(err.Error()).To(ContainSubstring("some error"))
 		})
 	})
 
+	Context("when the product slug cannot be found", func() {
+		BeforeEach(func() {
+			checkRequest.Source.ProductSlug = "some prodcut"
+		})
+
+		It("returns an error suggesting the closest slugs", func() {
+			_, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("provided product_slug: 'some prodcut' cannot be found, did you mean one of: ['some product']"))
+			Expect(err).To(MatchError(check.SlugNotFoundError{
+				Key:         "product_slug",
+				Slug:        "some prodcut",
+				Suggestions: []string{"some product"},
+			}))
+			Expect(fakePivnetClient.ReleasesForProductSlugCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the stemcell slug cannot be found", func() {
+		BeforeEach(func() {
+			checkRequest.Source.StemcellSlug = "completely different"
+		})
+
+		It("returns an error without suggestions", func() {
+			_, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(Equal("provided stemcell_slug: 'completely different' cannot be found"))
+		})
+	})
+
+	Context("when the dependency slug cannot be found", func() {
+		BeforeEach(func() {
+			checkRequest.Source.StemcellSlug = ""
+			checkRequest.Source.DependencySlug = "ops-manger"
+		})
+
+		It("returns an error suggesting the closest slugs", func() {
+			_, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("provided dependency_slug: 'ops-manger' cannot be found, did you mean one of: ['ops-manager']"))
+		})
+	})
+
+	Context("when there is an error listing products", func() {
+		BeforeEach(func() {
+			fakePivnetClient.ProductsReturns(nil, fmt.Errorf("some products error"))
+		})
+
+		It("returns an error", func() {
+			_, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("some products error"))
+		})
+	})
+
 	Context("when there is an error getting releases", func() {
 		BeforeEach(func() {
 			releasesErr = fmt.Errorf("some error")
 		})
 
 		It("returns an error", func() {
-			_, err := checkCommand.Run(checkRequest)
+			_, err := checkCommand.Run(context.Background(), checkRequest)
 			Expect(err).To(HaveOccurred())
 
 			Expect(err.Error()).To(ContainSubstring("some error"))
 		})
 	})
 
+	Context("when the check times out during a call", func() {
+		var ctx *interrupt.Context
+
+		BeforeEach(func() {
+			ctx = interrupt.NewContext(context.Background(), time.Millisecond)
+			DeferCleanup(ctx.Stop)
+		})
+
+		JustBeforeEach(func() {
+			fakePivnetClient.ReleasesForProductSlugStub = func(string) ([]pivnet.Release, error) {
+				<-ctx.Done()
+				return nil, errors.New("context canceled")
+			}
+		})
+
+		It("returns an error describing the call", func() {
+			_, err := checkCommand.Run(ctx, checkRequest)
+			Expect(err).To(MatchError("listing releases of 'some product' timed out after 1ms"))
+
+			var interruptedErr check.InterruptedError
+			Expect(errors.As(err, &interruptedErr)).To(BeTrue())
+			Expect(interruptedErr.Call).To(Equal("listing releases of 'some product'"))
+
+			Expect(errors.Is(err, interrupt.TimeoutError{Timeout: time.Millisecond})).To(BeTrue())
+		})
+	})
+
+	Context("when the check has already been interrupted", func() {
+		It("makes no further calls", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := checkCommand.Run(ctx, checkRequest)
+			Expect(err).To(MatchError("listing release types context canceled"))
+
+			Expect(fakePivnetClient.ReleaseTypesCallCount()).To(Equal(0))
+			Expect(fakePivnetClient.ReleasesForProductSlugCallCount()).To(Equal(0))
+		})
+	})
+
 	Describe("when a version is provided", func() {
 		Context("when the version is the latest", func() {
 			BeforeEach(func() {
@@ -340,7 +781,7 @@ var _ = Describe("Check", func() {
 			})
 
 			It("returns the most recent version", func() {
-				response, err := checkCommand.Run(checkRequest)
+				response, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).NotTo(HaveOccurred())
 
 				productVersionWithFingerprintA := productVersionsWithFingerprints[0]
@@ -371,7 +812,7 @@ var _ = Describe("Check", func() {
 			})
 
 			It("returns the most recent versions, including the version specified", func() {
-				response, err := checkCommand.Run(checkRequest)
+				response, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).NotTo(HaveOccurred())
 
 				productVersionWithFingerprintA := productVersionsWithFingerprints[0] // 1.2.3#time1
@@ -394,7 +835,7 @@ var _ = Describe("Check", func() {
 
 	Context("when the release type is specified", func() {
 		BeforeEach(func() {
-			checkRequest.Source.ReleaseType = string(releaseTypes[1])
+			checkRequest.Source.ReleaseType = concourse.StringList{string(releaseTypes[1])}
 
 			filteredProductReleases = []pivnet.Release{productReleases[1]}
 
@@ -403,7 +844,7 @@ var _ = Describe("Check", func() {
 		})
 
 		It("returns the most recent version with that release type", func() {
-			response, err := checkCommand.Run(checkRequest)
+			response, err := checkCommand.Run(context.Background(), checkRequest)
 			Expect(err).NotTo(HaveOccurred())
 
 			productVersionWithFingerprintC := productVersionsWithFingerprints[1]
@@ -416,11 +857,11 @@ var _ = Describe("Check", func() {
 
 		Context("when the release type is invalid", func() {
 			BeforeEach(func() {
-				checkRequest.Source.ReleaseType = "not a valid release type"
+				checkRequest.Source.ReleaseType = concourse.StringList{"not a valid release type"}
 			})
 
 			It("returns an error", func() {
-				_, err := checkCommand.Run(checkRequest)
+				_, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).To(HaveOccurred())
 
 				Expect(err.Error()).To(MatchRegexp(".*release type.*one of"))
@@ -430,13 +871,51 @@ var _ = Describe("Check", func() {
 			})
 		})
 
+		Context("when multiple release types are specified", func() {
+			BeforeEach(func() {
+				checkRequest.Source.ReleaseType = concourse.StringList{string(releaseTypes[0]), string(releaseTypes[2])}
+			})
+
+			JustBeforeEach(func() {
+				fakeFilter.ReleasesByReleaseTypeReturnsOnCall(0, []pivnet.Release{productReleases[0]}, nil)
+				fakeFilter.ReleasesByReleaseTypeReturnsOnCall(1, []pivnet.Release{productReleases[2]}, nil)
+			})
+
+			It("filters by each release type and keeps releases matching any of them", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeFilter.ReleasesByReleaseTypeCallCount()).To(Equal(2))
+				_, firstReleaseType := fakeFilter.ReleasesByReleaseTypeArgsForCall(0)
+				Expect(firstReleaseType).To(Equal(releaseTypes[0]))
+				_, secondReleaseType := fakeFilter.ReleasesByReleaseTypeArgsForCall(1)
+				Expect(secondReleaseType).To(Equal(releaseTypes[2]))
+
+				Expect(response).To(HaveLen(1))
+				Expect(response[0].ProductVersion).To(Equal(productVersionsWithFingerprints[0]))
+			})
+
+			Context("when one of the release types is invalid", func() {
+				BeforeEach(func() {
+					checkRequest.Source.ReleaseType = concourse.StringList{string(releaseTypes[0]), "not a valid release type"}
+				})
+
+				It("returns an error naming the invalid release type", func() {
+					_, err := checkCommand.Run(context.Background(), checkRequest)
+					Expect(err).To(HaveOccurred())
+
+					Expect(err.Error()).To(ContainSubstring("provided release type: 'not a valid release type'"))
+				})
+			})
+		})
+
 		Context("when filtering returns an error", func() {
 			BeforeEach(func() {
 				releasesByReleaseTypeErr = fmt.Errorf("some release type error")
 			})
 
 			It("returns the error", func() {
-				_, err := checkCommand.Run(checkRequest)
+				_, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).To(HaveOccurred())
 
 				Expect(err).To(Equal(releasesByReleaseTypeErr))
@@ -446,7 +925,7 @@ var _ = Describe("Check", func() {
 
 	Context("when the product version is specified", func() {
 		BeforeEach(func() {
-			checkRequest.Source.ReleaseType = string(releaseTypes[1])
+			checkRequest.Source.ReleaseType = concourse.StringList{string(releaseTypes[1])}
 
 			filteredProductReleases = []pivnet.Release{productReleases[1]}
 
@@ -455,11 +934,11 @@ var _ = Describe("Check", func() {
 		})
 
 		BeforeEach(func() {
-			checkRequest.Source.ProductVersion = "C"
+			checkRequest.Source.ProductVersion = concourse.StringList{"C"}
 		})
 
 		It("returns the newest release with that version without error", func() {
-			response, err := checkCommand.Run(checkRequest)
+			response, err := checkCommand.Run(context.Background(), checkRequest)
 			Expect(err).NotTo(HaveOccurred())
 
 			productVersionWithFingerprintC := productVersionsWithFingerprints[1]
@@ -474,7 +953,7 @@ var _ = Describe("Check", func() {
 			})
 
 			It("returns the error", func() {
-				_, err := checkCommand.Run(checkRequest)
+				_, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).To(HaveOccurred())
 
 				Expect(err).To(Equal(releasesByVersionErr))
@@ -482,6 +961,292 @@ var _ = Describe("Check", func() {
 		})
 	})
 
+	Context("when neither a stemcell slug nor a dependency slug is provided", func() {
+		var opsManagerDependency pivnet.ReleaseDependency
+
+		BeforeEach(func() {
+			checkRequest.Source.StemcellSlug = ""
+
+			opsManagerDependency = pivnet.ReleaseDependency{
+				Release: pivnet.DependentRelease{
+					ID:      31,
+					Version: "2.10.12",
+					Product: pivnet.Product{Slug: "ops-manager"},
+				},
+			}
+
+			jammyDependency := allReleaseDependencies[0]
+			jammyDependency.Release.Product.Slug = "stemcells-ubuntu-jammy"
+
+			fakePivnetClient.ReleaseDependenciesReturns([]pivnet.ReleaseDependency{opsManagerDependency, jammyDependency}, nil)
+			fakePivnetClient.GetReleaseReturns(stemcellReleases[0], nil)
+		})
+
+		It("detects the stemcell slug and records it in the version", func() {
+			response, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response).To(Equal(concourse.CheckResponse{
+				{
+					ProductVersion:  productVersionsWithFingerprints[0],
+					StemcellVersion: stemcellVersionsWithFingerprints[0],
+					StemcellSlug:    "stemcells-ubuntu-jammy",
+				},
+			}))
+
+			Expect(fakePivnetClient.ReleaseDependenciesCallCount()).To(Equal(1))
+			slug, version := fakePivnetClient.GetReleaseArgsForCall(0)
+			Expect(slug).To(Equal("stemcells-ubuntu-jammy"))
+			Expect(version).To(Equal("100.21"))
+		})
+
+		Context("when the product release depends on several stemcell families", func() {
+			BeforeEach(func() {
+				xenialDependency := allReleaseDependencies[1]
+				xenialDependency.Release.Product.Slug = "stemcells-ubuntu-xenial"
+				jammyDependency := allReleaseDependencies[0]
+				jammyDependency.Release.Product.Slug = "stemcells-ubuntu-jammy"
+
+				fakePivnetClient.ReleaseDependenciesReturns([]pivnet.ReleaseDependency{jammyDependency, xenialDependency}, nil)
+			})
+
+			It("returns an error listing the stemcell slugs", func() {
+				_, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("cannot detect which stemcell to track"))
+				Expect(err.Error()).To(ContainSubstring("['stemcells-ubuntu-jammy', 'stemcells-ubuntu-xenial']"))
+			})
+		})
+
+		Context("when the product release does not depend on a stemcell", func() {
+			BeforeEach(func() {
+				fakePivnetClient.ReleaseDependenciesReturns([]pivnet.ReleaseDependency{opsManagerDependency}, nil)
+			})
+
+			It("returns an error", func() {
+				_, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("cannot detect a stemcell dependency for product release 'some product/1.2.3'"))
+			})
+		})
+
+		Context("when the detected stemcell family changes between product releases", func() {
+			stemcellDependency := func(slug string, version string) pivnet.ReleaseDependency {
+				return pivnet.ReleaseDependency{
+					Release: pivnet.DependentRelease{
+						Version: version,
+						Product: pivnet.Product{Slug: slug},
+					},
+				}
+			}
+
+			BeforeEach(func() {
+				checkRequest.Version = concourse.Version{
+					ProductVersion:  productVersionsWithFingerprints[2], // 1.2.4#time3
+					StemcellVersion: "100.21#jammy-time",
+					StemcellSlug:    "stemcells-ubuntu-jammy",
+				}
+
+				fakePivnetClient.ReleaseDependenciesStub = func(_ string, releaseID int) ([]pivnet.ReleaseDependency, error) {
+					if releaseID == productReleases[0].ID {
+						return []pivnet.ReleaseDependency{
+							stemcellDependency("stemcells-ubuntu-xenial", "621.2"),
+							stemcellDependency("stemcells-ubuntu-xenial", "100.21"),
+						}, nil
+					}
+					return []pivnet.ReleaseDependency{stemcellDependency("stemcells-ubuntu-jammy", "100.21")}, nil
+				}
+				fakePivnetClient.GetReleaseStub = func(slug string, version string) (pivnet.Release, error) {
+					fingerprints := map[string]string{
+						"stemcells-ubuntu-jammy":  "jammy-time",
+						"stemcells-ubuntu-xenial": "xenial-time",
+					}
+					return pivnet.Release{Version: version, SoftwareFilesUpdatedAt: fingerprints[slug]}, nil
+				}
+			})
+
+			It("gets the releases of each family and only applies the last seen stemcell version to its own family", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response).To(Equal(concourse.CheckResponse{
+					{
+						ProductVersion:  productVersionsWithFingerprints[2],
+						StemcellVersion: "100.21#jammy-time",
+						StemcellSlug:    "stemcells-ubuntu-jammy",
+					},
+					{
+						ProductVersion:  productVersionsWithFingerprints[1],
+						StemcellVersion: "100.21#jammy-time",
+						StemcellSlug:    "stemcells-ubuntu-jammy",
+					},
+					{
+						ProductVersion:  productVersionsWithFingerprints[0],
+						StemcellVersion: "621.2#xenial-time",
+						StemcellSlug:    "stemcells-ubuntu-xenial",
+					},
+				}))
+
+				Expect(fakePivnetClient.GetReleaseCallCount()).To(Equal(3))
+			})
+		})
+	})
+
+	Context("when tracking the newest release per minor line", func() {
+		BeforeEach(func() {
+			checkRequest.Source.Track = concourse.TrackPerMinor
+
+			productReleases[1].ReleaseDate = "2023-06-01"
+			productReleases[2].ReleaseDate = "2023-06-10"
+
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[1]}, allReleaseDependenciesErr)
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, []pivnet.ReleaseDependency{allReleaseDependencies[2]}, allReleaseDependenciesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(0, stemcellReleases[1], stemcellReleasesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(1, stemcellReleases[2], stemcellReleasesErr)
+		})
+
+		It("returns the newest pair of each line ordered by when they were published", func() {
+			response, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response).To(Equal(concourse.CheckResponse{
+				{ProductVersion: productVersionsWithFingerprints[1], StemcellVersion: stemcellVersionsWithFingerprints[1]},
+				{ProductVersion: productVersionsWithFingerprints[2], StemcellVersion: stemcellVersionsWithFingerprints[2]},
+			}))
+
+			_, releaseID := fakePivnetClient.ReleaseDependenciesArgsForCall(0)
+			Expect(releaseID).To(Equal(productReleases[1].ID))
+			_, releaseID = fakePivnetClient.ReleaseDependenciesArgsForCall(1)
+			Expect(releaseID).To(Equal(productReleases[2].ID))
+		})
+
+		Context("when a newer line has already been seen", func() {
+			BeforeEach(func() {
+				checkRequest.Version = concourse.Version{
+					ProductVersion:  productVersionsWithFingerprints[1],
+					StemcellVersion: stemcellVersionsWithFingerprints[1],
+				}
+			})
+
+			It("still returns the newest pair of the older line", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response).To(HaveLen(2))
+				Expect(response[1].ProductVersion).To(Equal(productVersionsWithFingerprints[2]))
+			})
+		})
+
+		Context("when a new patch on an older line is published after the last seen release of a newer line", func() {
+			BeforeEach(func() {
+				productReleases[0].ReleaseDate = "2023-05-01"
+
+				checkRequest.Version = concourse.Version{
+					ProductVersion:  productVersionsWithFingerprints[1], // 2.3.4#time2, published 2023-06-01
+					StemcellVersion: stemcellVersionsWithFingerprints[1],
+				}
+			})
+
+			It("returns the last seen pair followed by the new patch as the newest version", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response).To(Equal(concourse.CheckResponse{
+					{ProductVersion: productVersionsWithFingerprints[1], StemcellVersion: stemcellVersionsWithFingerprints[1]},
+					{ProductVersion: productVersionsWithFingerprints[2], StemcellVersion: stemcellVersionsWithFingerprints[2]},
+				}))
+			})
+		})
+
+		Context("when a new patch on an older line is published on the same date as the last seen release of a newer line", func() {
+			BeforeEach(func() {
+				productReleases[1].ReleaseDate = "2023-06-10"
+
+				checkRequest.Version = concourse.Version{
+					ProductVersion:  productVersionsWithFingerprints[1], // 2.3.4#time2, published 2023-06-10
+					StemcellVersion: stemcellVersionsWithFingerprints[1],
+				}
+			})
+
+			It("returns the new patch after the last seen pair as the newest version", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response).To(Equal(concourse.CheckResponse{
+					{ProductVersion: productVersionsWithFingerprints[1], StemcellVersion: stemcellVersionsWithFingerprints[1]},
+					{ProductVersion: productVersionsWithFingerprints[2], StemcellVersion: stemcellVersionsWithFingerprints[2]},
+				}))
+			})
+		})
+
+		Context("when tracking per major line", func() {
+			BeforeEach(func() {
+				checkRequest.Source.Track = concourse.TrackPerMajor
+
+				productReleases[0].Version = "2.4.0"
+				productVersionsWithFingerprints[0] = "2.4.0#time1"
+
+				productReleases[0].ReleaseDate = "2023-05-01"
+			})
+
+			It("returns the newest pair of each major line", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response).To(HaveLen(2))
+				Expect(response[0].ProductVersion).To(Equal(productVersionsWithFingerprints[0]))
+				Expect(response[1].ProductVersion).To(Equal(productVersionsWithFingerprints[2]))
+			})
+		})
+	})
+
+	Context("when the product version constraint is specified", func() {
+		BeforeEach(func() {
+			checkRequest.Source.ProductVersionConstraint = ">=2 <3"
+
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[1]}, allReleaseDependenciesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(0, stemcellReleases[1], stemcellReleasesErr)
+		})
+
+		It("returns the newest release satisfying the constraint without error", func() {
+			response, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeFilter.ReleasesByVersionCallCount()).To(Equal(0))
+
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].ProductVersion).To(Equal(productVersionsWithFingerprints[1]))
+		})
+
+		Context("when no release satisfies the constraint", func() {
+			BeforeEach(func() {
+				checkRequest.Source.ProductVersionConstraint = "~3.0"
+			})
+
+			It("returns an error", func() {
+				_, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("cannot find specified product release"))
+			})
+		})
+
+		Context("when the constraint is invalid", func() {
+			BeforeEach(func() {
+				checkRequest.Source.ProductVersionConstraint = ">=two"
+			})
+
+			It("returns an error", func() {
+				_, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("invalid version constraint"))
+			})
+		})
+	})
+
 	Context("when sorting by semver", func() {
 		var (
 			semverOrderedProductReleases []pivnet.Release
@@ -521,7 +1286,7 @@ var _ = Describe("Check", func() {
 			It("returns in ascending semver order", func() {
 				Expect(fakeSorter.SortBySemverCallCount()).To(Equal(0))
 
-				response, err := checkCommand.Run(checkRequest)
+				response, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(response).To(HaveLen(4))
@@ -550,7 +1315,7 @@ var _ = Describe("Check", func() {
 			})
 
 			It("returns error", func() {
-				_, err := checkCommand.Run(checkRequest)
+				_, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).To(HaveOccurred())
 
 				Expect(err).To(Equal(semverErr))
@@ -572,7 +1337,7 @@ var _ = Describe("Check", func() {
 			})
 
 			It("returns error", func() {
-				_, err := checkCommand.Run(checkRequest)
+				_, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).To(HaveOccurred())
 
 				Expect(err).To(Equal(semverErr))
@@ -580,6 +1345,70 @@ var _ = Describe("Check", func() {
 		})
 	})
 
+	Context("when sorting stemcell first", func() {
+		BeforeEach(func() {
+			checkRequest.Source.SortBy = concourse.SortByStemcellFirst
+
+			checkRequest.Version = concourse.Version{
+				ProductVersion:  productVersionsWithFingerprints[0], // 1.2.3#time1
+				StemcellVersion: stemcellVersionsWithFingerprints[0], // 100.21#time1
+			}
+
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{allReleaseDependencies[0]}, allReleaseDependenciesErr)
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, []pivnet.ReleaseDependency{allReleaseDependencies[1]}, allReleaseDependenciesErr)
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(2, []pivnet.ReleaseDependency{allReleaseDependencies[0]}, allReleaseDependenciesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(0, stemcellReleases[0], stemcellReleasesErr)
+			fakePivnetClient.GetReleaseReturnsOnCall(1, stemcellReleases[1], stemcellReleasesErr)
+
+			fakeSorter.SortBySemverReturnsOnCall(0, []pivnet.Release{productReleases[1], productReleases[2], productReleases[0]}, nil)
+			fakeSorter.SortBySemverReturnsOnCall(1, []pivnet.Release{stemcellReleases[0]}, nil)
+			fakeSorter.SortBySemverReturnsOnCall(2, []pivnet.Release{stemcellReleases[1]}, nil)
+			fakeSorter.SortBySemverReturnsOnCall(3, []pivnet.Release{stemcellReleases[0]}, nil)
+		})
+
+		It("returns in ascending stemcell semver order before product semver order", func() {
+			response, err := checkCommand.Run(context.Background(), checkRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(response).To(Equal(concourse.CheckResponse{
+				{ProductVersion: productVersionsWithFingerprints[0], StemcellVersion: stemcellVersionsWithFingerprints[0]},
+				{ProductVersion: productVersionsWithFingerprints[1], StemcellVersion: stemcellVersionsWithFingerprints[0]},
+				{ProductVersion: productVersionsWithFingerprints[2], StemcellVersion: stemcellVersionsWithFingerprints[1]},
+			}))
+
+			Expect(fakeSorter.SortBySemverCallCount()).To(Equal(4))
+		})
+
+		Context("when the stemcell versions are not fingerprinted", func() {
+			var unfingerprintedStemcellReleases []pivnet.Release
+
+			BeforeEach(func() {
+				unfingerprintedStemcellReleases = []pivnet.Release{stemcellReleases[0], stemcellReleases[1]}
+				for i := range unfingerprintedStemcellReleases {
+					unfingerprintedStemcellReleases[i].SoftwareFilesUpdatedAt = ""
+				}
+
+				fakePivnetClient.GetReleaseReturnsOnCall(0, unfingerprintedStemcellReleases[0], stemcellReleasesErr)
+				fakePivnetClient.GetReleaseReturnsOnCall(1, unfingerprintedStemcellReleases[1], stemcellReleasesErr)
+
+				fakeSorter.SortBySemverReturnsOnCall(1, []pivnet.Release{unfingerprintedStemcellReleases[0]}, nil)
+				fakeSorter.SortBySemverReturnsOnCall(2, []pivnet.Release{unfingerprintedStemcellReleases[1]}, nil)
+				fakeSorter.SortBySemverReturnsOnCall(3, []pivnet.Release{unfingerprintedStemcellReleases[0]}, nil)
+			})
+
+			It("returns in ascending stemcell semver order of the raw stemcell versions", func() {
+				response, err := checkCommand.Run(context.Background(), checkRequest)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(response).To(Equal(concourse.CheckResponse{
+					{ProductVersion: productVersionsWithFingerprints[0], StemcellVersion: "100.21"},
+					{ProductVersion: productVersionsWithFingerprints[1], StemcellVersion: "100.21"},
+					{ProductVersion: productVersionsWithFingerprints[2], StemcellVersion: "210.97"},
+				}))
+			})
+		})
+	})
+
 	Context("when sorting by last_updated", func() {
 		var (
 			updateSortedProductReleases []pivnet.Release
@@ -618,7 +1447,7 @@ var _ = Describe("Check", func() {
 			It("returns invokes sort by last_updated on sorter", func() {
 				Expect(fakeSorter.SortByLastUpdatedCallCount()).To(Equal(0))
 
-				response, err := checkCommand.Run(checkRequest)
+				response, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(response).To(HaveLen(3))
@@ -645,7 +1474,7 @@ var _ = Describe("Check", func() {
 			})
 
 			It("returns error", func() {
-				_, err := checkCommand.Run(checkRequest)
+				_, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).To(HaveOccurred())
 
 				Expect(err).To(Equal(semverErr))
@@ -667,7 +1496,7 @@ var _ = Describe("Check", func() {
 			})
 
 			It("returns error", func() {
-				_, err := checkCommand.Run(checkRequest)
+				_, err := checkCommand.Run(context.Background(), checkRequest)
 				Expect(err).To(HaveOccurred())
 
 				Expect(err).To(Equal(semverErr))
@@ -22,6 +22,18 @@ type FakePivnetClient struct {
 		result1 pivnet.Release
 		result2 error
 	}
+	ProductsStub        func() ([]pivnet.Product, error)
+	productsMutex       sync.RWMutex
+	productsArgsForCall []struct {
+	}
+	productsReturns struct {
+		result1 []pivnet.Product
+		result2 error
+	}
+	productsReturnsOnCall map[int]struct {
+		result1 []pivnet.Product
+		result2 error
+	}
 	ReleaseDependenciesStub        func(string, int) ([]pivnet.ReleaseDependency, error)
 	releaseDependenciesMutex       sync.RWMutex
 	releaseDependenciesArgsForCall []struct {
@@ -72,15 +84,16 @@ func (fake *FakePivnetClient) GetRelease(arg1 string, arg2 string) (pivnet.Relea
 		arg1 string
 		arg2 string
 	}{arg1, arg2})
+	stub := fake.GetReleaseStub
+	fakeReturns := fake.getReleaseReturns
 	fake.recordInvocation("GetRelease", []interface{}{arg1, arg2})
 	fake.getReleaseMutex.Unlock()
-	if fake.GetReleaseStub != nil {
-		return fake.GetReleaseStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.getReleaseReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -129,6 +142,62 @@ func (fake *FakePivnetClient) GetReleaseReturnsOnCall(i int, result1 pivnet.Rele
 	}{result1, result2}
 }
 
+func (fake *FakePivnetClient) Products() ([]pivnet.Product, error) {
+	fake.productsMutex.Lock()
+	ret, specificReturn := fake.productsReturnsOnCall[len(fake.productsArgsForCall)]
+	fake.productsArgsForCall = append(fake.productsArgsForCall, struct {
+	}{})
+	stub := fake.ProductsStub
+	fakeReturns := fake.productsReturns
+	fake.recordInvocation("Products", []interface{}{})
+	fake.productsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakePivnetClient) ProductsCallCount() int {
+	fake.productsMutex.RLock()
+	defer fake.productsMutex.RUnlock()
+	return len(fake.productsArgsForCall)
+}
+
+func (fake *FakePivnetClient) ProductsCalls(stub func() ([]pivnet.Product, error)) {
+	fake.productsMutex.Lock()
+	defer fake.productsMutex.Unlock()
+	fake.ProductsStub = stub
+}
+
+func (fake *FakePivnetClient) ProductsReturns(result1 []pivnet.Product, result2 error) {
+	fake.productsMutex.Lock()
+	defer fake.productsMutex.Unlock()
+	fake.ProductsStub = nil
+	fake.productsReturns = struct {
+		result1 []pivnet.Product
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePivnetClient) ProductsReturnsOnCall(i int, result1 []pivnet.Product, result2 error) {
+	fake.productsMutex.Lock()
+	defer fake.productsMutex.Unlock()
+	fake.ProductsStub = nil
+	if fake.productsReturnsOnCall == nil {
+		fake.productsReturnsOnCall = make(map[int]struct {
+			result1 []pivnet.Product
+			result2 error
+		})
+	}
+	fake.productsReturnsOnCall[i] = struct {
+		result1 []pivnet.Product
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakePivnetClient) ReleaseDependencies(arg1 string, arg2 int) ([]pivnet.ReleaseDependency, error) {
 	fake.releaseDependenciesMutex.Lock()
 	ret, specificReturn := fake.releaseDependenciesReturnsOnCall[len(fake.releaseDependenciesArgsForCall)]
@@ -136,15 +205,16 @@ func (fake *FakePivnetClient) ReleaseDependencies(arg1 string, arg2 int) ([]pivn
 		arg1 string
 		arg2 int
 	}{arg1, arg2})
+	stub := fake.ReleaseDependenciesStub
+	fakeReturns := fake.releaseDependenciesReturns
 	fake.recordInvocation("ReleaseDependencies", []interface{}{arg1, arg2})
 	fake.releaseDependenciesMutex.Unlock()
-	if fake.ReleaseDependenciesStub != nil {
-		return fake.ReleaseDependenciesStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.releaseDependenciesReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -198,15 +268,16 @@ func (fake *FakePivnetClient) ReleaseTypes() ([]pivnet.ReleaseType, error) {
 	ret, specificReturn := fake.releaseTypesReturnsOnCall[len(fake.releaseTypesArgsForCall)]
 	fake.releaseTypesArgsForCall = append(fake.releaseTypesArgsForCall, struct {
 	}{})
+	stub := fake.ReleaseTypesStub
+	fakeReturns := fake.releaseTypesReturns
 	fake.recordInvocation("ReleaseTypes", []interface{}{})
 	fake.releaseTypesMutex.Unlock()
-	if fake.ReleaseTypesStub != nil {
-		return fake.ReleaseTypesStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.releaseTypesReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -254,15 +325,16 @@ func (fake *FakePivnetClient) ReleasesForProductSlug(arg1 string) ([]pivnet.Rele
 	fake.releasesForProductSlugArgsForCall = append(fake.releasesForProductSlugArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.ReleasesForProductSlugStub
+	fakeReturns := fake.releasesForProductSlugReturns
 	fake.recordInvocation("ReleasesForProductSlug", []interface{}{arg1})
 	fake.releasesForProductSlugMutex.Unlock()
-	if fake.ReleasesForProductSlugStub != nil {
-		return fake.ReleasesForProductSlugStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.releasesForProductSlugReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
@@ -316,6 +388,8 @@ func (fake *FakePivnetClient) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.getReleaseMutex.RLock()
 	defer fake.getReleaseMutex.RUnlock()
+	fake.productsMutex.RLock()
+	defer fake.productsMutex.RUnlock()
 	fake.releaseDependenciesMutex.RLock()
 	defer fake.releaseDependenciesMutex.RUnlock()
 	fake.releaseTypesMutex.RLock()
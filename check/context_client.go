@@ -0,0 +1,100 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pivotal-cf/go-pivnet/v7"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/interrupt"
+)
+
+// InterruptedError : a call to Pivotal Network was cut short, or not made, as the check timed out or was interrupted
+type InterruptedError struct {
+	Call  string
+	Cause error
+}
+
+func (e InterruptedError) Error() string {
+	return fmt.Sprintf("%s %s", e.Call, e.Cause)
+}
+
+// Unwrap : the reason the check ended, e.g. an interrupt.TimeoutError
+func (e InterruptedError) Unwrap() error {
+	return e.Cause
+}
+
+// Hint : how to resolve the error
+func (e InterruptedError) Hint() string {
+	var timeoutErr interrupt.TimeoutError
+	if errors.As(e.Cause, &timeoutErr) {
+		return fmt.Sprintf("Raise the timeout above %s, or remove it to wait for Pivotal Network indefinitely.", timeoutErr.Timeout)
+	}
+	return ""
+}
+
+// contextClient makes the calls of the wrapped client only while the context has not ended, describing the call
+// which was cut short when it does
+type contextClient struct {
+	ctx    context.Context
+	client pivnetClient
+}
+
+func (c contextClient) ReleaseTypes() ([]pivnet.ReleaseType, error) {
+	call := "listing release types"
+	if err := c.interrupted(call, nil); err != nil {
+		return nil, err
+	}
+
+	releaseTypes, err := c.client.ReleaseTypes()
+	return releaseTypes, c.interrupted(call, err)
+}
+
+func (c contextClient) ReleasesForProductSlug(productSlug string) ([]pivnet.Release, error) {
+	call := fmt.Sprintf("listing releases of '%s'", productSlug)
+	if err := c.interrupted(call, nil); err != nil {
+		return nil, err
+	}
+
+	releases, err := c.client.ReleasesForProductSlug(productSlug)
+	return releases, c.interrupted(call, err)
+}
+
+func (c contextClient) ReleaseDependencies(productSlug string, releaseID int) ([]pivnet.ReleaseDependency, error) {
+	call := fmt.Sprintf("listing dependencies of release %d of '%s'", releaseID, productSlug)
+	if err := c.interrupted(call, nil); err != nil {
+		return nil, err
+	}
+
+	releaseDependencies, err := c.client.ReleaseDependencies(productSlug, releaseID)
+	return releaseDependencies, c.interrupted(call, err)
+}
+
+func (c contextClient) GetRelease(productSlug string, version string) (pivnet.Release, error) {
+	call := fmt.Sprintf("getting release '%s/%s'", productSlug, version)
+	if err := c.interrupted(call, nil); err != nil {
+		return pivnet.Release{}, err
+	}
+
+	release, err := c.client.GetRelease(productSlug, version)
+	return release, c.interrupted(call, err)
+}
+
+func (c contextClient) Products() ([]pivnet.Product, error) {
+	call := "listing products"
+	if err := c.interrupted(call, nil); err != nil {
+		return nil, err
+	}
+
+	products, err := c.client.Products()
+	return products, c.interrupted(call, err)
+}
+
+// interrupted returns an InterruptedError for the call once the context has ended, and err otherwise
+func (c contextClient) interrupted(call string, err error) error {
+	if cause := interrupt.Cause(c.ctx); cause != nil {
+		return InterruptedError{Call: call, Cause: cause}
+	}
+	return err
+}
@@ -0,0 +1,258 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotFound : matched by errors.Is for the releases, dependencies and slugs which cannot be found
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidSource : matched by errors.Is for sources which cannot be checked as they are configured
+	ErrInvalidSource = errors.New("invalid source")
+)
+
+type hinted interface {
+	Hint() string
+}
+
+// Hint : how to resolve err when it is, or wraps, one of the errors of a check, otherwise empty
+func Hint(err error) string {
+	var h hinted
+	if errors.As(err, &h) {
+		return h.Hint()
+	}
+	return ""
+}
+
+// ProductReleaseNotFoundError : none of the releases of the product are left once filtered
+type ProductReleaseNotFoundError struct {
+	ProductSlug string
+}
+
+func (e ProductReleaseNotFoundError) Error() string {
+	return fmt.Sprintf("cannot find specified product release of '%s'", e.ProductSlug)
+}
+
+// Is : matches ErrNotFound
+func (e ProductReleaseNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Hint : how to resolve the error
+func (e ProductReleaseNotFoundError) Hint() string {
+	return fmt.Sprintf(
+		"Check that release_type, product_version, product_version_constraint, availability, min_release_age, "+
+			"exclude_product_versions and opsman_version all match at least one release of '%s'.",
+		e.ProductSlug,
+	)
+}
+
+// MissingDependencyError : the product release does not declare the dependency, or any dependency when StemcellSlug
+// is empty
+type MissingDependencyError struct {
+	ProductSlug      string
+	ProductVersion   string
+	ProductReleaseID int
+	StemcellSlug     string
+}
+
+func (e MissingDependencyError) Error() string {
+	if e.StemcellSlug == "" {
+		return fmt.Sprintf(
+			"cannot find specified dependencies for product release '%s/%s' (release ID %d)",
+			e.ProductSlug,
+			e.ProductVersion,
+			e.ProductReleaseID,
+		)
+	}
+	return fmt.Sprintf(
+		"cannot find specified '%s' dependencies for product release '%s/%s' (release ID %d)",
+		e.StemcellSlug,
+		e.ProductSlug,
+		e.ProductVersion,
+		e.ProductReleaseID,
+	)
+}
+
+// Is : matches ErrNotFound
+func (e MissingDependencyError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Hint : how to resolve the error
+func (e MissingDependencyError) Hint() string {
+	if e.StemcellSlug == "" {
+		return fmt.Sprintf(
+			"'%s/%s' declares no dependencies on Pivotal Network, skip it with exclude_product_versions: ['%s'].",
+			e.ProductSlug,
+			e.ProductVersion,
+			e.ProductVersion,
+		)
+	}
+	return fmt.Sprintf(
+		"Check that stemcell_slug or dependency_slug is one of the dependencies of '%s/%s' on Pivotal Network, "+
+			"or skip it with exclude_product_versions: ['%s'].",
+		e.ProductSlug,
+		e.ProductVersion,
+		e.ProductVersion,
+	)
+}
+
+// DependencyReleaseNotFoundError : none of the releases of the dependency the product release declares are left once
+// filtered
+type DependencyReleaseNotFoundError struct {
+	ProductSlug    string
+	ProductVersion string
+	StemcellSlug   string
+}
+
+func (e DependencyReleaseNotFoundError) Error() string {
+	return fmt.Sprintf(
+		"cannot find specified '%s' release for product release '%s/%s'",
+		e.StemcellSlug,
+		e.ProductSlug,
+		e.ProductVersion,
+	)
+}
+
+// Is : matches ErrNotFound
+func (e DependencyReleaseNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Hint : how to resolve the error
+func (e DependencyReleaseNotFoundError) Hint() string {
+	return fmt.Sprintf(
+		"Check that the '%s' releases '%s/%s' depends on are still published on Pivotal Network.",
+		e.StemcellSlug,
+		e.ProductSlug,
+		e.ProductVersion,
+	)
+}
+
+// DependentReleaseNotFoundError : none of the releases of the dependency which the products depend on are left once
+// filtered, in the reverse mode
+type DependentReleaseNotFoundError struct {
+	StemcellSlug string
+	ProductSlugs []string
+}
+
+func (e DependentReleaseNotFoundError) Error() string {
+	return fmt.Sprintf(
+		"cannot find specified '%s' release with dependent product releases of ['%s']",
+		e.StemcellSlug,
+		strings.Join(e.ProductSlugs, "', '"),
+	)
+}
+
+// Is : matches ErrNotFound
+func (e DependentReleaseNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Hint : how to resolve the error
+func (e DependentReleaseNotFoundError) Hint() string {
+	return fmt.Sprintf(
+		"Check that the filtered releases of ['%s'] declare '%s' as a dependency, and that availability, "+
+			"min_release_age.stemcell and exclude_stemcell_versions leave at least one of those '%s' releases.",
+		strings.Join(e.ProductSlugs, "', '"),
+		e.StemcellSlug,
+		e.StemcellSlug,
+	)
+}
+
+// StemcellDetectionError : the stemcell to track cannot be detected from the dependencies of the product release, as
+// it depends on none or several of StemcellSlugs
+type StemcellDetectionError struct {
+	ProductSlug    string
+	ProductVersion string
+	StemcellSlugs  []string
+}
+
+func (e StemcellDetectionError) Error() string {
+	if len(e.StemcellSlugs) == 0 {
+		return fmt.Sprintf(
+			"cannot detect a stemcell dependency for product release '%s/%s', provide stemcell_slug or dependency_slug",
+			e.ProductSlug,
+			e.ProductVersion,
+		)
+	}
+	return fmt.Sprintf(
+		"cannot detect which stemcell to track for product release '%s/%s' as it depends on: ['%s'], provide stemcell_slug as one of them",
+		e.ProductSlug,
+		e.ProductVersion,
+		strings.Join(e.StemcellSlugs, "', '"),
+	)
+}
+
+// Is : matches ErrInvalidSource
+func (e StemcellDetectionError) Is(target error) bool {
+	return target == ErrInvalidSource
+}
+
+// Hint : how to resolve the error
+func (e StemcellDetectionError) Hint() string {
+	if len(e.StemcellSlugs) == 0 {
+		return "Set dependency_slug to the slug of the dependency to track, as shown in the dependencies of the product release on Pivotal Network."
+	}
+	return fmt.Sprintf("Set stemcell_slug to one of: ['%s']", strings.Join(e.StemcellSlugs, "', '"))
+}
+
+// SlugNotFoundError : the slug of the source property Key is not the slug of any product on Pivotal Network
+type SlugNotFoundError struct {
+	Key         string
+	Slug        string
+	Suggestions []string
+}
+
+func (e SlugNotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("provided %s: '%s' cannot be found", e.Key, e.Slug)
+	}
+	return fmt.Sprintf(
+		"provided %s: '%s' cannot be found, did you mean one of: ['%s']",
+		e.Key,
+		e.Slug,
+		strings.Join(e.Suggestions, "', '"),
+	)
+}
+
+// Is : matches ErrNotFound
+func (e SlugNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Hint : how to resolve the error
+func (e SlugNotFoundError) Hint() string {
+	return fmt.Sprintf(
+		"Set %s to the slug at the end of the product's URL on Pivotal Network, e.g. 'elastic-runtime' for "+
+			"https://network.pivotal.io/products/elastic-runtime, and check that the api_token has access to it.",
+		e.Key,
+	)
+}
+
+// ReleaseTypeError : the release type is not one of the ReleaseTypes of Pivotal Network
+type ReleaseTypeError struct {
+	ReleaseType  string
+	ReleaseTypes []string
+}
+
+func (e ReleaseTypeError) Error() string {
+	return fmt.Sprintf(
+		"provided release type: '%s' must be one of: ['%s']",
+		e.ReleaseType,
+		strings.Join(e.ReleaseTypes, "', '"),
+	)
+}
+
+// Is : matches ErrInvalidSource
+func (e ReleaseTypeError) Is(target error) bool {
+	return target == ErrInvalidSource
+}
+
+// Hint : how to resolve the error
+func (e ReleaseTypeError) Hint() string {
+	return "Release types are case sensitive and must be spelled exactly as listed, e.g. 'Major Release'."
+}
@@ -0,0 +1,243 @@
+package check_test
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/check"
+	"github.com/shanman190/pivnet-product-stemcell-resource/interrupt"
+)
+
+var _ = Describe("Errors", func() {
+	wrap := func(err error) error {
+		return fmt.Errorf("some context: %w", err)
+	}
+
+	Describe("ProductReleaseNotFoundError", func() {
+		err := check.ProductReleaseNotFoundError{ProductSlug: "some-product"}
+
+		It("names the product", func() {
+			Expect(err.Error()).To(Equal("cannot find specified product release of 'some-product'"))
+		})
+
+		It("is a not found error", func() {
+			Expect(errors.Is(wrap(err), check.ErrNotFound)).To(BeTrue())
+			Expect(errors.Is(wrap(err), check.ErrInvalidSource)).To(BeFalse())
+		})
+
+		It("can be unwrapped", func() {
+			var target check.ProductReleaseNotFoundError
+			Expect(errors.As(wrap(err), &target)).To(BeTrue())
+			Expect(target.ProductSlug).To(Equal("some-product"))
+		})
+
+		It("hints at the filters", func() {
+			Expect(check.Hint(wrap(err))).To(ContainSubstring("release_type, product_version"))
+			Expect(check.Hint(wrap(err))).To(ContainSubstring("'some-product'"))
+		})
+	})
+
+	Describe("MissingDependencyError", func() {
+		err := check.MissingDependencyError{
+			ProductSlug:      "some-product",
+			ProductVersion:   "1.2.3",
+			ProductReleaseID: 42,
+			StemcellSlug:     "stemcells-ubuntu-jammy",
+		}
+
+		It("names the product release and the dependency", func() {
+			Expect(err.Error()).To(Equal("cannot find specified 'stemcells-ubuntu-jammy' dependencies for product release 'some-product/1.2.3' (release ID 42)"))
+		})
+
+		It("is a not found error", func() {
+			Expect(errors.Is(wrap(err), check.ErrNotFound)).To(BeTrue())
+		})
+
+		It("can be unwrapped", func() {
+			var target check.MissingDependencyError
+			Expect(errors.As(wrap(err), &target)).To(BeTrue())
+			Expect(target).To(Equal(err))
+		})
+
+		It("hints at the slug and excluding the product version", func() {
+			Expect(check.Hint(err)).To(ContainSubstring("stemcell_slug or dependency_slug"))
+			Expect(check.Hint(err)).To(ContainSubstring("exclude_product_versions: ['1.2.3']"))
+		})
+
+		Context("when the product release has no dependencies", func() {
+			err := check.MissingDependencyError{ProductSlug: "some-product", ProductVersion: "1.2.3", ProductReleaseID: 42}
+
+			It("names the product release", func() {
+				Expect(err.Error()).To(Equal("cannot find specified dependencies for product release 'some-product/1.2.3' (release ID 42)"))
+			})
+
+			It("hints at excluding the product version", func() {
+				Expect(check.Hint(err)).To(Equal("'some-product/1.2.3' declares no dependencies on Pivotal Network, skip it with exclude_product_versions: ['1.2.3']."))
+			})
+		})
+	})
+
+	Describe("DependencyReleaseNotFoundError", func() {
+		err := check.DependencyReleaseNotFoundError{
+			ProductSlug:    "some-product",
+			ProductVersion: "1.2.3",
+			StemcellSlug:   "stemcells-ubuntu-jammy",
+		}
+
+		It("names the product release and the dependency", func() {
+			Expect(err.Error()).To(Equal("cannot find specified 'stemcells-ubuntu-jammy' release for product release 'some-product/1.2.3'"))
+		})
+
+		It("is a not found error", func() {
+			Expect(errors.Is(wrap(err), check.ErrNotFound)).To(BeTrue())
+		})
+
+		It("can be unwrapped", func() {
+			var target check.DependencyReleaseNotFoundError
+			Expect(errors.As(wrap(err), &target)).To(BeTrue())
+			Expect(target).To(Equal(err))
+		})
+
+		It("hints at the dependency releases", func() {
+			Expect(check.Hint(err)).To(ContainSubstring("'stemcells-ubuntu-jammy' releases 'some-product/1.2.3' depends on"))
+		})
+	})
+
+	Describe("DependentReleaseNotFoundError", func() {
+		err := check.DependentReleaseNotFoundError{
+			StemcellSlug: "stemcells-ubuntu-jammy",
+			ProductSlugs: []string{"some-product", "some-other-product"},
+		}
+
+		It("names the dependency and the products", func() {
+			Expect(err.Error()).To(Equal("cannot find specified 'stemcells-ubuntu-jammy' release with dependent product releases of ['some-product', 'some-other-product']"))
+		})
+
+		It("is a not found error", func() {
+			Expect(errors.Is(wrap(err), check.ErrNotFound)).To(BeTrue())
+		})
+
+		It("can be unwrapped", func() {
+			var target check.DependentReleaseNotFoundError
+			Expect(errors.As(wrap(err), &target)).To(BeTrue())
+			Expect(target).To(Equal(err))
+		})
+
+		It("hints at the dependency filters", func() {
+			Expect(check.Hint(err)).To(ContainSubstring("exclude_stemcell_versions"))
+		})
+	})
+
+	Describe("StemcellDetectionError", func() {
+		err := check.StemcellDetectionError{
+			ProductSlug:    "some-product",
+			ProductVersion: "1.2.3",
+			StemcellSlugs:  []string{"stemcells-ubuntu-jammy", "stemcells-ubuntu-xenial"},
+		}
+
+		It("names the product release and the stemcells it depends on", func() {
+			Expect(err.Error()).To(Equal("cannot detect which stemcell to track for product release 'some-product/1.2.3' as it depends on: ['stemcells-ubuntu-jammy', 'stemcells-ubuntu-xenial'], provide stemcell_slug as one of them"))
+		})
+
+		It("is an invalid source error", func() {
+			Expect(errors.Is(wrap(err), check.ErrInvalidSource)).To(BeTrue())
+			Expect(errors.Is(wrap(err), check.ErrNotFound)).To(BeFalse())
+		})
+
+		It("can be unwrapped", func() {
+			var target check.StemcellDetectionError
+			Expect(errors.As(wrap(err), &target)).To(BeTrue())
+			Expect(target).To(Equal(err))
+		})
+
+		It("hints at the stemcell slugs to choose from", func() {
+			Expect(check.Hint(err)).To(Equal("Set stemcell_slug to one of: ['stemcells-ubuntu-jammy', 'stemcells-ubuntu-xenial']"))
+		})
+
+		Context("when the product release depends on no stemcell", func() {
+			err := check.StemcellDetectionError{ProductSlug: "some-product", ProductVersion: "1.2.3"}
+
+			It("names the product release", func() {
+				Expect(err.Error()).To(Equal("cannot detect a stemcell dependency for product release 'some-product/1.2.3', provide stemcell_slug or dependency_slug"))
+			})
+
+			It("hints at the dependency slug", func() {
+				Expect(check.Hint(err)).To(ContainSubstring("Set dependency_slug"))
+			})
+		})
+	})
+
+	Describe("SlugNotFoundError", func() {
+		err := check.SlugNotFoundError{
+			Key:         "stemcell_slug",
+			Slug:        "stemcells-ubuntu-jamy",
+			Suggestions: []string{"stemcells-ubuntu-jammy"},
+		}
+
+		It("names the property, the slug and the suggestions", func() {
+			Expect(err.Error()).To(Equal("provided stemcell_slug: 'stemcells-ubuntu-jamy' cannot be found, did you mean one of: ['stemcells-ubuntu-jammy']"))
+		})
+
+		It("is a not found error", func() {
+			Expect(errors.Is(wrap(err), check.ErrNotFound)).To(BeTrue())
+		})
+
+		It("can be unwrapped", func() {
+			var target check.SlugNotFoundError
+			Expect(errors.As(wrap(err), &target)).To(BeTrue())
+			Expect(target).To(Equal(err))
+		})
+
+		It("hints at where to find the slug", func() {
+			Expect(check.Hint(err)).To(HavePrefix("Set stemcell_slug to the slug at the end of the product's URL"))
+		})
+	})
+
+	Describe("ReleaseTypeError", func() {
+		err := check.ReleaseTypeError{
+			ReleaseType:  "major release",
+			ReleaseTypes: []string{"Major Release", "Minor Release"},
+		}
+
+		It("names the release type and the supported ones", func() {
+			Expect(err.Error()).To(Equal("provided release type: 'major release' must be one of: ['Major Release', 'Minor Release']"))
+		})
+
+		It("is an invalid source error", func() {
+			Expect(errors.Is(wrap(err), check.ErrInvalidSource)).To(BeTrue())
+		})
+
+		It("can be unwrapped", func() {
+			var target check.ReleaseTypeError
+			Expect(errors.As(wrap(err), &target)).To(BeTrue())
+			Expect(target).To(Equal(err))
+		})
+
+		It("hints that release types are case sensitive", func() {
+			Expect(check.Hint(err)).To(ContainSubstring("case sensitive"))
+		})
+	})
+
+	Describe("InterruptedError", func() {
+		It("hints at raising the timeout when it timed out", func() {
+			err := check.InterruptedError{Call: "listing release types", Cause: interrupt.TimeoutError{Timeout: time.Minute}}
+			Expect(check.Hint(wrap(err))).To(Equal("Raise the timeout above 1m0s, or remove it to wait for Pivotal Network indefinitely."))
+		})
+
+		It("has no hint when it was interrupted by a signal", func() {
+			err := check.InterruptedError{Call: "listing release types", Cause: interrupt.SignalError{Signal: syscall.SIGTERM}}
+			Expect(check.Hint(err)).To(BeEmpty())
+		})
+	})
+
+	Describe("Hint", func() {
+		It("is empty for other errors", func() {
+			Expect(check.Hint(errors.New("some error"))).To(BeEmpty())
+		})
+	})
+})
@@ -1,24 +1,40 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/pivotal-cf/go-pivnet/v7"
 	"github.com/pivotal-cf/go-pivnet/v7/logger"
-	"github.com/pivotal-cf/go-pivnet/v7/logshim"
 	"github.com/pivotal-cf/pivnet-resource/v3/filter"
-	"github.com/pivotal-cf/pivnet-resource/v3/gp"
 	"github.com/pivotal-cf/pivnet-resource/v3/semver"
 	"github.com/pivotal-cf/pivnet-resource/v3/sorter"
+	"github.com/pivotal-cf/pivnet-resource/v3/ui"
 	"github.com/pivotal-cf/pivnet-resource/v3/useragent"
-	"github.com/robdimsdale/sanitizer"
+	"io"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
+	"syscall"
+	"github.com/shanman190/pivnet-product-stemcell-resource/auth"
 	"github.com/shanman190/pivnet-product-stemcell-resource/check"
 	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/interrupt"
+	"github.com/shanman190/pivnet-product-stemcell-resource/logfiles"
+	"github.com/shanman190/pivnet-product-stemcell-resource/logging"
+	"github.com/shanman190/pivnet-product-stemcell-resource/pivnetclient"
+	"github.com/shanman190/pivnet-product-stemcell-resource/sanitizer"
+	"github.com/shanman190/pivnet-product-stemcell-resource/schema"
+	"github.com/shanman190/pivnet-product-stemcell-resource/tokencache"
+	"github.com/shanman190/pivnet-product-stemcell-resource/transport"
 	"github.com/shanman190/pivnet-product-stemcell-resource/validator"
 )
 
+const (
+	schemaFlag = "--schema"
+)
+
 var (
 	// version is deliberately left uninitialized so it can be set at compile-time
 	version string
@@ -29,31 +45,98 @@ func main() {
 		version = "dev"
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == schemaFlag {
+		err := schema.Print(os.Stdout, schema.CheckRequest)
+		if err != nil {
+			log.Fatalf("Exiting with error: %s", err)
+		}
+		return
+	}
+
+	sanitize := sanitizer.NewSanitizer(nil)
+	log.SetOutput(sanitize.Writer(os.Stderr))
+	uiPrinter := ui.NewUIPrinter(sanitize.Writer(os.Stderr))
+
+	err := run(sanitize)
+	if err != nil {
+		uiPrinter.PrintErrorln(err)
+		if hint := check.Hint(err); hint != "" {
+			uiPrinter.Println(hint)
+		}
+		os.Exit(1)
+	}
+}
+
+// run checks for new versions, returning the error to exit with so that its deferred calls run before exiting
+func run(sanitize *sanitizer.Sanitizer) (err error) {
 	var input concourse.CheckRequest
 
-	logFile, err := ioutil.TempFile("", "pivnet-check.log")
+	uiPrinter := ui.NewUIPrinter(sanitize.Writer(os.Stderr))
+
+	// problems with the request are reported together with those found by validating it
+	decodeErr := validator.Decode(os.Stdin, &input)
+	if _, ok := decodeErr.(validator.Error); decodeErr != nil && !ok {
+		return decodeErr
+	}
+
+	sanitize.LearnAll(concourse.SanitizedSource(input.Source))
+
+	logFiles := logfiles.NewRotator(
+		logfiles.Dir(logfiles.DefaultRoot(), input.Source),
+		input.Source.LogRetention,
+		logfiles.MaxAge,
+		logfiles.MaxTotalSize,
+		check.SystemClock{},
+	)
+
+	var logWriter io.Writer = ioutil.Discard
+	logFile, err := logFiles.Create()
 	if err != nil {
-		log.Printf("could not create log file")
+		log.Printf("could not create log file: %s", err)
+	} else {
+		defer logFile.Close()
+		logWriter = logFile
 	}
 
-	logger := log.New(logFile, "", log.LstdFlags)
+	logger := log.New(sanitize.Writer(logWriter), "", log.LstdFlags)
+	defer func() {
+		if err != nil {
+			logger.Printf("Exiting with error: %s", err)
+		}
+	}()
 
 	logger.Printf("PivNet Product Stemcell Resource version: %s", version)
 
-	err = json.NewDecoder(os.Stdin).Decode(&input)
-	if err != nil {
-		log.Fatalf("Exiting with error: %s", err)
+	if logFile != nil {
+		err = logFiles.Prune(logFile.Name())
+		if err != nil {
+			logger.Printf("could not remove old log files: %s", err)
+		}
 	}
 
-	sanitized := concourse.SanitizedSource(input.Source)
-	logger.SetOutput(sanitizer.NewSanitizer(sanitized, logFile))
+	ls := logging.NewLogger(logger, log.New(sanitize.Writer(os.Stderr), "", 0), input.Source.EffectiveLogLevel())
+	if logFile != nil {
+		ls.Debug(fmt.Sprintf("Logging to %s", logFile.Name()))
+	}
 
-	verbose := false
-	ls := logshim.NewLogShim(logger, logger, verbose)
+	err = validator.Join(decodeErr, validator.NewCheckValidator(input).Validate())
+	if err != nil {
+		return err
+	}
+
+	input.Source.APIToken, err = input.Source.ResolveAPIToken()
+	if err != nil {
+		return err
+	}
+	sanitize.LearnAll(concourse.SanitizedSource(input.Source))
 
-	err = validator.NewCheckValidator(input).Validate()
+	err = auth.RequireRefreshToken(input.Source.APIToken, input.Source.RequireRefreshToken)
 	if err != nil {
-		log.Fatalf("Exiting with error: %s", err)
+		return err
+	}
+
+	if auth.IsLegacyToken(input.Source.APIToken) {
+		uiPrinter.PrintDeprecationln(auth.LegacyTokenDeprecation)
 	}
 
 	var endpoint string
@@ -63,14 +146,33 @@ func main() {
 		endpoint = pivnet.DefaultHost
 	}
 
+	timeout, err := concourse.ParseTimeout(input.Source.Timeout)
+	if err != nil {
+		return err
+	}
+
+	ctx := interrupt.NewContext(context.Background(), timeout, os.Interrupt, syscall.SIGTERM)
+	defer ctx.Stop()
+
+	httpTransport, err := transport.NewTransport(input.Source)
+	if err != nil {
+		return err
+	}
+	t := sanitize.Transport(ls.Transport(transport.WithContext(ctx, httpTransport)))
+
 	apiToken := input.Source.APIToken
-	token := pivnet.NewAccessTokenOrLegacyToken(apiToken, endpoint, input.Source.SkipSSLValidation, "Pivnet Product Stemcell Resource")
+	var token pivnet.AccessTokenService = pivnetclient.NewAccessTokenOrLegacyToken(apiToken, endpoint, "Pivnet Product Stemcell Resource", t)
+	if input.Source.CacheAccessToken {
+		token = tokencache.NewTokenCache(tokencache.DefaultDir(), check.SystemClock{}, ls).AccessTokenService(apiToken, endpoint, token)
+	}
+	token = sanitize.AccessTokenService(token)
 
 	client := newPivnetClientWithToken(
 		token,
 		endpoint,
 		input.Source.SkipSSLValidation,
 		useragent.UserAgent(version, "check", input.Source.ProductSlug),
+		t,
 		ls,
 	)
 
@@ -85,28 +187,26 @@ func main() {
 		f,
 		client,
 		s,
-		logFile.Name(),
-	).Run(input)
+		check.SystemClock{},
+	).Run(ctx, input)
 	if err != nil {
-		log.Fatalf("Exiting with error: %s", err)
+		return err
 	}
 
-	err = json.NewEncoder(os.Stdout).Encode(response)
-	if err != nil {
-		log.Fatalf("Exiting with error: %s", err)
-	}
+	return json.NewEncoder(os.Stdout).Encode(response)
 }
 
-func newPivnetClientWithToken(token pivnet.AccessTokenOrLegacyToken, host string, skipSSLValidation bool, userAgent string, logger logger.Logger) *gp.Client {
+func newPivnetClientWithToken(token pivnet.AccessTokenService, host string, skipSSLValidation bool, userAgent string, t http.RoundTripper, logger logger.Logger) *pivnetclient.Client {
 	clientConfig := pivnet.ClientConfig{
 		Host:              host,
 		UserAgent:         userAgent,
 		SkipSSLValidation: skipSSLValidation,
 	}
 
-	return gp.NewClient(
+	return pivnetclient.NewClient(
 		token,
 		clientConfig,
+		t,
 		logger,
 	)
 }
\ No newline at end of file
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/shanman190/pivnet-product-stemcell-resource/versions"
+	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
+	"path/filepath"
+	"syscall"
 
 	"github.com/fatih/color"
 	"github.com/pivotal-cf/go-pivnet/v7"
@@ -15,17 +21,31 @@ import (
 	pivnetconcourse "github.com/pivotal-cf/pivnet-resource/v3/concourse"
 	"github.com/pivotal-cf/pivnet-resource/v3/downloader"
 	"github.com/pivotal-cf/pivnet-resource/v3/filter"
-	"github.com/pivotal-cf/pivnet-resource/v3/gp"
 	"github.com/pivotal-cf/pivnet-resource/v3/in"
 	"github.com/pivotal-cf/pivnet-resource/v3/in/filesystem"
 	"github.com/pivotal-cf/pivnet-resource/v3/ui"
 	"github.com/pivotal-cf/pivnet-resource/v3/useragent"
-	"github.com/robdimsdale/sanitizer"
 
+	"github.com/shanman190/pivnet-product-stemcell-resource/auth"
 	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/dependents"
+	"github.com/shanman190/pivnet-product-stemcell-resource/interrupt"
+	"github.com/shanman190/pivnet-product-stemcell-resource/pivnetclient"
+	"github.com/shanman190/pivnet-product-stemcell-resource/planner"
+	"github.com/shanman190/pivnet-product-stemcell-resource/sanitizer"
+	"github.com/shanman190/pivnet-product-stemcell-resource/schema"
+	"github.com/shanman190/pivnet-product-stemcell-resource/sorting"
+	"github.com/shanman190/pivnet-product-stemcell-resource/transport"
 	"github.com/shanman190/pivnet-product-stemcell-resource/validator"
 )
 
+const (
+	dependentsFilename  = "dependents.json"
+	upgradePlanFilename = "upgrade-plan.json"
+
+	schemaFlag = "--schema"
+)
+
 var (
 	// version is deliberately left uninitialized so it can be set at compile-time
 	version string
@@ -36,9 +56,31 @@ func main() {
 		version = "dev"
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == schemaFlag {
+		err := schema.Print(os.Stdout, schema.InRequest)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	color.NoColor = false
 
-	logWriter := os.Stderr
+	sanitize := sanitizer.NewSanitizer(nil)
+
+	uiPrinter := ui.NewUIPrinter(sanitize.Writer(os.Stderr))
+
+	err := run(sanitize)
+	if err != nil {
+		uiPrinter.PrintErrorln(err)
+		os.Exit(1)
+	}
+}
+
+// run fetches the version, returning the error to exit with so that its deferred calls run before exiting
+func run(sanitize *sanitizer.Sanitizer) error {
+	logWriter := sanitize.Writer(os.Stderr)
 	uiPrinter := ui.NewUIPrinter(logWriter)
 
 	logger := log.New(logWriter, "", log.LstdFlags)
@@ -46,24 +88,22 @@ func main() {
 	logger.Printf("PivNet Product Stemcell Resource version: %s", version)
 
 	if len(os.Args) < 2 {
-		uiPrinter.PrintErrorlnf(
+		return fmt.Errorf(
 			"not enough args - usage: %s <sources directory>",
 			os.Args[0],
 		)
-		os.Exit(1)
 	}
 
 	downloadDir := os.Args[1]
 
 	var input concourse.InRequest
-	err := json.NewDecoder(os.Stdin).Decode(&input)
-	if err != nil {
-		uiPrinter.PrintErrorln(err)
-		os.Exit(1)
+	// problems with the request are reported together with those found by validating it
+	decodeErr := validator.Decode(os.Stdin, &input)
+	if _, ok := decodeErr.(validator.Error); decodeErr != nil && !ok {
+		return decodeErr
 	}
 
-	sanitized := concourse.SanitizedSource(input.Source)
-	logger.SetOutput(sanitizer.NewSanitizer(sanitized, logWriter))
+	sanitize.LearnAll(concourse.SanitizedSource(input.Source))
 
 	verbose := input.Source.Verbose
 	ls := logshim.NewLogShim(logger, logger, verbose)
@@ -71,16 +111,25 @@ func main() {
 	ls.Debug("Verbose output enabled")
 	logger.Printf("Creating download directory: %s", downloadDir)
 
-	err = os.MkdirAll(downloadDir, os.ModePerm)
+	err := os.MkdirAll(downloadDir, os.ModePerm)
 	if err != nil {
-		uiPrinter.PrintErrorln(err)
-		os.Exit(1)
+		return err
 	}
 
-	err = validator.NewInValidator(input).Validate()
+	err = validator.Join(decodeErr, validator.NewInValidator(input).Validate())
 	if err != nil {
-		uiPrinter.PrintErrorln(err)
-		os.Exit(1)
+		return err
+	}
+
+	input.Source.APIToken, err = input.Source.ResolveAPIToken()
+	if err != nil {
+		return err
+	}
+	sanitize.LearnAll(concourse.SanitizedSource(input.Source))
+
+	err = auth.RequireRefreshToken(input.Source.APIToken, input.Source.RequireRefreshToken)
+	if err != nil {
+		return err
 	}
 
 	var endpoint string
@@ -90,11 +139,25 @@ func main() {
 		endpoint = pivnet.DefaultHost
 	}
 
+	timeout, err := concourse.ParseTimeout(input.Source.Timeout)
+	if err != nil {
+		return err
+	}
+
+	ctx := interrupt.NewContext(context.Background(), timeout, os.Interrupt, syscall.SIGTERM)
+	defer ctx.Stop()
+
+	httpTransport, err := transport.NewTransport(input.Source)
+	if err != nil {
+		return err
+	}
+	t := sanitize.Transport(transport.WithContext(ctx, httpTransport))
+
 	apiToken := input.Source.APIToken
-	token := pivnet.NewAccessTokenOrLegacyToken(apiToken, endpoint, input.Source.SkipSSLValidation, "Pivnet Resource")
+	token := sanitize.AccessTokenService(pivnetclient.NewAccessTokenOrLegacyToken(apiToken, endpoint, "Pivnet Resource", t))
 
-	if len(apiToken) < 20 {
-		uiPrinter.PrintDeprecationln("The use of static Pivnet API tokens is deprecated and will be removed. Please see https://network.pivotal.io/docs/api#how-to-authenticate for details.")
+	if auth.IsLegacyToken(apiToken) {
+		uiPrinter.PrintDeprecationln(auth.LegacyTokenDeprecation)
 	}
 
 	client := newPivnetClientWithToken(
@@ -102,6 +165,7 @@ func main() {
 		endpoint,
 		input.Source.SkipSSLValidation,
 		useragent.UserAgent(version, "get", input.Source.ProductSlug),
+		t,
 		ls,
 	)
 
@@ -117,6 +181,11 @@ func main() {
 
 	pivnetInput := convertToPivnetInput(input)
 
+	cleanup, err := interrupt.NewCleanup(downloadDir)
+	if err != nil {
+		return err
+	}
+
 	pivnetResponse, err := in.NewInCommand(
 		ls,
 		client,
@@ -128,59 +197,101 @@ func main() {
 		archive,
 	).Run(pivnetInput)
 	if err != nil {
-		uiPrinter.PrintErrorln(err)
-		os.Exit(1)
+		if interrupt.Cause(ctx) != nil {
+			logger.Printf("Removing partially downloaded files from: %s", downloadDir)
+			cleanupErr := cleanup.Run()
+			if cleanupErr != nil {
+				logger.Printf("Cannot remove partially downloaded files: %s", cleanupErr)
+			}
+		}
+		return err
 	}
 
-	response := convertFromPivnetResponse(input.Version.ProductVersion, pivnetResponse)
+	response := convertFromPivnetResponse(input.Source, input.Version.ProductVersion, pivnetResponse)
+	response.Version.StemcellSlug = input.Version.StemcellSlug
+
+	if input.Source.Mode == concourse.ModeReverse {
+		dependencyVersion := pivnetInput.Source.ProductVersion
+
+		logger.Printf("Gathering product releases which depend on '%s/%s'", input.Source.TrackedDependencySlug(), dependencyVersion)
+		dependentReleases, err := dependents.NewFinder(ls, f, client).Find(input.Source)
+		if err != nil {
+			return err
+		}
+
+		dependentReleasesForVersion := dependentReleases[dependencyVersion]
+		if dependentReleasesForVersion == nil {
+			dependentReleasesForVersion = []dependents.ProductRelease{}
+		}
+
+		err = writeJSON(filepath.Join(downloadDir, dependentsFilename), dependentReleasesForVersion)
+		if err != nil {
+			return err
+		}
+
+		for _, dependentRelease := range dependentReleasesForVersion {
+			response.Metadata = append(response.Metadata, concourse.Metadata{
+				Name:  "dependent_product_release",
+				Value: fmt.Sprintf("%s/%s", dependentRelease.ProductSlug, dependentRelease.Version),
+			})
+		}
+	}
 
-	err = json.NewEncoder(os.Stdout).Encode(response)
-	if err != nil {
-		uiPrinter.PrintErrorln(err)
-		os.Exit(1)
+	if input.Params.UpgradeFrom != "" {
+		upgradeFrom := stripFingerprint(input.Params.UpgradeFrom)
+		upgradeTo := stripFingerprint(input.Version.ProductVersion)
+
+		logger.Printf("Planning upgrade of '%s' from '%s' to '%s'", input.Source.ProductSlug, upgradeFrom, upgradeTo)
+		plan, err := planner.NewPlanner(ls, client).Plan(
+			input.Source.ProductSlug,
+			input.TrackedDependencySlug(),
+			upgradeFrom,
+			upgradeTo,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = writeJSON(filepath.Join(downloadDir, upgradePlanFilename), plan)
+		if err != nil {
+			return err
+		}
 	}
+
+	return json.NewEncoder(os.Stdout).Encode(response)
 }
 
-func newPivnetClientWithToken(token pivnet.AccessTokenService, host string, skipSSLValidation bool, userAgent string, logger logger.Logger) *gp.Client {
+func newPivnetClientWithToken(token pivnet.AccessTokenService, host string, skipSSLValidation bool, userAgent string, t http.RoundTripper, logger logger.Logger) *pivnetclient.Client {
 	clientConfig := pivnet.ClientConfig{
 		Host:              host,
 		UserAgent:         userAgent,
 		SkipSSLValidation: skipSSLValidation,
 	}
 
-	return gp.NewClient(
+	return pivnetclient.NewClient(
 		token,
 		clientConfig,
+		t,
 		logger,
 	)
 }
 
 func convertToPivnetInput(input concourse.InRequest) pivnetconcourse.InRequest {
-	stemcellVersion, _, _ := versions.SplitIntoVersionAndFingerprint(input.Version.StemcellVersion)
-
-	var pivnetSortyBy pivnetconcourse.SortBy
-	if input.Source.SortBy == concourse.SortByNone {
-		pivnetSortyBy = pivnetconcourse.SortByNone
-	} else if input.Source.SortBy == concourse.SortBySemver {
-		pivnetSortyBy = pivnetconcourse.SortBySemver
-	} else if input.Source.SortBy == concourse.SortByLastUpdated {
-		pivnetSortyBy = pivnetconcourse.SortByLastUpdated
-	}
+	dependencyVersion, _, _ := versions.SplitIntoVersionAndFingerprint(input.Version.TrackedDependencyVersion())
 
 	return pivnetconcourse.InRequest{
 		Source:  pivnetconcourse.Source{
 			APIToken: 		   input.Source.APIToken,
-			ProductSlug: 	   input.Source.StemcellSlug,
-			ProductVersion:    stemcellVersion,
+			ProductSlug: 	   input.TrackedDependencySlug(),
+			ProductVersion:    dependencyVersion,
 			Endpoint:		   input.Source.Endpoint,
-			ReleaseType:	   input.Source.ReleaseType,
-			SortBy:			   pivnetSortyBy,
+			SortBy:			   sorting.PivnetSortBy(input.Source.SortBy),
 			SkipSSLValidation: input.Source.SkipSSLValidation,
 			CopyMetadata:	   input.Source.CopyMetadata,
 			Verbose: 		   input.Source.Verbose,
 		},
 		Version: pivnetconcourse.Version{
-			ProductVersion: input.Version.StemcellVersion,
+			ProductVersion: input.Version.TrackedDependencyVersion(),
 		},
 		Params:  pivnetconcourse.InParams{
 			Globs:  input.Params.Globs,
@@ -189,7 +300,7 @@ func convertToPivnetInput(input concourse.InRequest) pivnetconcourse.InRequest {
 	}
 }
 
-func convertFromPivnetResponse(productVersion string, response pivnetconcourse.InResponse) concourse.InResponse {
+func convertFromPivnetResponse(source concourse.Source, productVersion string, response pivnetconcourse.InResponse) concourse.InResponse {
 	var metadata []concourse.Metadata
 	for _, pivnetMetadata := range response.Metadata {
 		metadata = append(metadata, concourse.Metadata{
@@ -198,10 +309,25 @@ func convertFromPivnetResponse(productVersion string, response pivnetconcourse.I
 		})
 	}
 	return concourse.InResponse{
-		Version:  concourse.Version{
-			ProductVersion: productVersion,
-			StemcellVersion: response.Version.ProductVersion,
-		},
+		Version:  concourse.NewVersion(source, productVersion, response.Version.ProductVersion),
 		Metadata: metadata,
 	}
+}
+
+func writeJSON(path string, v interface{}) error {
+	contents, err := json.Marshal(v)
+	if err != nil {
+		// Untested as it is too hard to force json.Marshal to return an error
+		return err
+	}
+
+	return ioutil.WriteFile(path, contents, os.ModePerm)
+}
+
+func stripFingerprint(versionWithFingerprint string) string {
+	version, _, err := versions.SplitIntoVersionAndFingerprint(versionWithFingerprint)
+	if err != nil {
+		return versionWithFingerprint
+	}
+	return version
 }
\ No newline at end of file
@@ -7,6 +7,12 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/pivotal-cf/pivnet-resource/v3/ui"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/schema"
+)
+
+const (
+	schemaFlag = "--schema"
 )
 
 var (
@@ -19,6 +25,15 @@ func main() {
 		version = "dev"
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == schemaFlag {
+		err := schema.Print(os.Stdout, schema.OutRequest)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	color.NoColor = false
 
 	logWriter := os.Stderr
@@ -0,0 +1,13 @@
+package concourse_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestConcourse(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Concourse Suite")
+}
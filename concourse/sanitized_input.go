@@ -1,5 +1,10 @@
 package concourse
 
+import (
+	"net/url"
+	"strings"
+)
+
 // SanitizedSource : sanitize the input source to prevent credential leaking
 func SanitizedSource(source Source) map[string]string {
 	s := make(map[string]string)
@@ -8,5 +13,40 @@ func SanitizedSource(source Source) map[string]string {
 		s[source.APIToken] = "***REDACTED-PIVNET_API_TOKEN***"
 	}
 
+	if source.ClientKey != "" {
+		s[source.ClientKey] = "***REDACTED-PIVNET_CLIENT_KEY***"
+	}
+
+	for _, proxy := range []string{source.HTTPProxy, source.HTTPSProxy} {
+		for _, password := range proxyPasswords(proxy) {
+			s[password] = "***REDACTED-PROXY_PASSWORD***"
+		}
+	}
+
 	return s
+}
+
+// proxyPasswords : the password embedded in a proxy URL, both as written and unescaped
+func proxyPasswords(proxy string) []string {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.User == nil {
+		return nil
+	}
+
+	password, ok := u.User.Password()
+	if !ok || password == "" {
+		return nil
+	}
+
+	passwords := []string{password}
+	if i := strings.Index(u.User.String(), ":"); i >= 0 {
+		if escaped := u.User.String()[i+1:]; escaped != password {
+			passwords = append(passwords, escaped)
+		}
+	}
+	return passwords
 }
\ No newline at end of file
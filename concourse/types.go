@@ -1,29 +1,274 @@
 package concourse
 
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // SortBy : type alias for better readability
 type SortBy string
 
 const (
 	// SortByNone : Use sorting as-is from PivNet API
-	SortByNone   	  SortBy = "none"
+	SortByNone SortBy = "none"
 	// SortBySemver : Sort the responses by Semantic Versioning rules
-	SortBySemver 	  SortBy = "semver"
+	SortBySemver SortBy = "semver"
 	// SortByLastUpdated : Sort the responses by their last updated time
 	SortByLastUpdated SortBy = "last_updated"
+	// SortByReleaseDate : Sort the responses by their release date
+	SortByReleaseDate SortBy = "release_date"
+	// SortByStemcellFirst : Sort the responses by Semantic Versioning rules, ordering versions by their stemcell
+	// version before their product version
+	SortByStemcellFirst SortBy = "stemcell_first"
+)
+
+// SortBys : every supported SortBy
+var SortBys = []SortBy{
+	SortByNone,
+	SortBySemver,
+	SortByLastUpdated,
+	SortByReleaseDate,
+	SortByStemcellFirst,
+}
+
+// Valid : whether the SortBy is supported, or not provided
+func (s SortBy) Valid() bool {
+	if s == "" {
+		return true
+	}
+	for _, sortBy := range SortBys {
+		if s == sortBy {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON : unmarshals a SortBy, leaving unsupported values for the validator to report with the other problems
+func (s *SortBy) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("must be a string")
+	}
+
+	*s = SortBy(str)
+	return nil
+}
+
+// SortByChoices : the supported SortBys, formatted for error messages
+func SortByChoices() string {
+	choices := make([]string, len(SortBys))
+	for i, sortBy := range SortBys {
+		choices[i] = string(sortBy)
+	}
+	return fmt.Sprintf("['%s']", strings.Join(choices, "', '"))
+}
+
+// Mode : type alias for better readability
+type Mode string
+
+const (
+	// ModeForward : Discover the dependency releases of the tracked product releases
+	ModeForward Mode = "forward"
+	// ModeReverse : Discover the tracked product releases which depend on each dependency release
+	ModeReverse Mode = "reverse"
 )
 
+// Track : type alias for better readability
+type Track string
+
+const (
+	// TrackPerMinor : Track the newest product release of each major.minor line
+	TrackPerMinor Track = "per_minor"
+	// TrackPerMajor : Track the newest product release of each major line
+	TrackPerMajor Track = "per_major"
+)
+
+// LogLevel : type alias for better readability
+type LogLevel string
+
+const (
+	// LogLevelError : Log only the errors a check fails with
+	LogLevelError LogLevel = "error"
+	// LogLevelInfo : Also log the progress of a check
+	LogLevelInfo LogLevel = "info"
+	// LogLevelDebug : Also log the details of the releases being considered
+	LogLevelDebug LogLevel = "debug"
+	// LogLevelTrace : Also log a summary of every request to Pivotal Network and its response
+	LogLevelTrace LogLevel = "trace"
+)
+
+// LogLevels : every supported LogLevel, from the least to the most verbose
+var LogLevels = []LogLevel{
+	LogLevelError,
+	LogLevelInfo,
+	LogLevelDebug,
+	LogLevelTrace,
+}
+
+// DefaultLogRetention : how many check log files are kept when log_retention is not provided
+const DefaultLogRetention = 5
+
 // Source : source structure for information provided from Concourse
 type Source struct {
-	APIToken          string `json:"api_token"`
-	ProductSlug       string `json:"product_slug"`
-	ProductVersion    string `json:"product_version"`
-	StemcellSlug	  string `json:"stemcell_slug"`
-	Endpoint          string `json:"endpoint"`
-	ReleaseType       string `json:"release_type"`
-	SortBy            SortBy `json:"sort_by"`
-	SkipSSLValidation bool   `json:"skip_ssl_verification"`
-	CopyMetadata      bool   `json:"copy_metadata"`
-	Verbose           bool   `json:"verbose"`
+	APIToken                 string        `json:"api_token"`
+	APITokenFile             string        `json:"api_token_file"`
+	APITokenEnv              string        `json:"api_token_env"`
+	RequireRefreshToken      bool          `json:"require_refresh_token"`
+	ProductSlug              string        `json:"product_slug"`
+	ProductSlugs             []string      `json:"product_slugs"`
+	ProductVersion           StringList    `json:"product_version"`
+	ProductVersionConstraint string        `json:"product_version_constraint"`
+	StemcellSlug             string        `json:"stemcell_slug"`
+	DependencySlug           string        `json:"dependency_slug"`
+	OpsManagerVersion        string        `json:"opsman_version"`
+	Endpoint                 string        `json:"endpoint"`
+	ReleaseType              StringList    `json:"release_type"`
+	Availability             []string      `json:"availability"`
+	ExcludePastEOGS          bool          `json:"exclude_past_eogs"`
+	MinReleaseAge            MinReleaseAge `json:"min_release_age"`
+	ExcludeProductVersions   []string      `json:"exclude_product_versions"`
+	ExcludeStemcellVersions  []string      `json:"exclude_stemcell_versions"`
+	SortBy                   SortBy        `json:"sort_by"`
+	Mode                     Mode          `json:"mode"`
+	Track                    Track         `json:"track"`
+	SkipSSLValidation        bool          `json:"skip_ssl_verification"`
+	CACerts                  []string      `json:"ca_certs"`
+	ClientCert               string        `json:"client_cert"`
+	ClientKey                string        `json:"client_key"`
+	HTTPProxy                string        `json:"http_proxy"`
+	HTTPSProxy               string        `json:"https_proxy"`
+	NoProxy                  string        `json:"no_proxy"`
+	CacheAccessToken         bool          `json:"cache_access_token"`
+	CopyMetadata             bool          `json:"copy_metadata"`
+	Verbose                  bool          `json:"verbose"`
+	Timeout                  string        `json:"timeout"`
+	LogRetention             int           `json:"log_retention"`
+	LogLevel                 LogLevel      `json:"log_level"`
+}
+
+// StringList : list of strings which may also be provided as a single string
+type StringList []string
+
+// UnmarshalJSON : unmarshals either a single string or a list of strings
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s == "" {
+			*l = nil
+		} else {
+			*l = StringList{s}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("must be a string or a list of strings")
+	}
+
+	*l = list
+	return nil
+}
+
+// MinReleaseAge : minimum age the product and stemcell releases must reach before being emitted
+type MinReleaseAge struct {
+	Product  string `json:"product"`
+	Stemcell string `json:"stemcell"`
+}
+
+// ParseReleaseAge : parses a release age given either as a Go duration (e.g. 36h) or as a number of days (e.g. 3d)
+func ParseReleaseAge(age string) (time.Duration, error) {
+	if age == "" {
+		return 0, nil
+	}
+
+	if strings.HasSuffix(age, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(age, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("invalid release age: '%s'", age)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(age)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid release age: '%s'", age)
+	}
+	return d, nil
+}
+
+// ParseTimeout : parses a timeout given as a Go duration (e.g. 10m), where no timeout is zero
+func ParseTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout: '%s'", timeout)
+	}
+	return d, nil
+}
+
+// EffectiveLogLevel : the log_level, falling back to debug when verbose and error otherwise
+func (s Source) EffectiveLogLevel() LogLevel {
+	switch {
+	case s.LogLevel != "":
+		return s.LogLevel
+	case s.Verbose:
+		return LogLevelDebug
+	default:
+		return LogLevelError
+	}
+}
+
+// TrackedProductSlugs : the slugs of the products being tracked, which are either the product_slugs or the product_slug
+func (s Source) TrackedProductSlugs() []string {
+	if len(s.ProductSlugs) > 0 {
+		return s.ProductSlugs
+	}
+	if s.ProductSlug != "" {
+		return []string{s.ProductSlug}
+	}
+	return nil
+}
+
+// TrackedDependencySlug : the slug of the dependency being tracked, which is either the dependency_slug or the stemcell_slug
+func (s Source) TrackedDependencySlug() string {
+	if s.DependencySlug != "" {
+		return s.DependencySlug
+	}
+	return s.StemcellSlug
+}
+
+// ResolveAPIToken : the api_token, or the token read from the api_token_file or the api_token_env environment variable
+func (s Source) ResolveAPIToken() (string, error) {
+	switch {
+	case s.APITokenFile != "":
+		contents, err := ioutil.ReadFile(s.APITokenFile)
+		if err != nil {
+			return "", fmt.Errorf("cannot read api_token_file: %s", err)
+		}
+		token := strings.TrimSpace(string(contents))
+		if token == "" {
+			return "", fmt.Errorf("api_token_file is empty: '%s'", s.APITokenFile)
+		}
+		return token, nil
+	case s.APITokenEnv != "":
+		token := strings.TrimSpace(os.Getenv(s.APITokenEnv))
+		if token == "" {
+			return "", fmt.Errorf("api_token_env environment variable is not set: '%s'", s.APITokenEnv)
+		}
+		return token, nil
+	default:
+		return s.APIToken, nil
+	}
 }
 
 // CheckRequest : request body for the check.Command
@@ -33,9 +278,30 @@ type CheckRequest struct {
 }
 
 // Version : version structure for information provided in Concourse
+//
+// Stemcell dependencies continue to be keyed as stemcell_version for backwards compatibility, while any other
+// dependency is keyed as dependency_version.
 type Version struct {
-	ProductVersion string `json:"product_version"`
-	StemcellVersion string `json:"stemcell_version"`
+	ProductVersion    string `json:"product_version,omitempty"`
+	StemcellVersion   string `json:"stemcell_version,omitempty"`
+	DependencyVersion string `json:"dependency_version,omitempty"`
+	StemcellSlug      string `json:"stemcell_slug,omitempty"`
+}
+
+// NewVersion : creates a Version for the given source, keying the dependency version the same way as the source keys its slug
+func NewVersion(source Source, productVersion string, dependencyVersion string) Version {
+	if source.DependencySlug != "" {
+		return Version{ProductVersion: productVersion, DependencyVersion: dependencyVersion}
+	}
+	return Version{ProductVersion: productVersion, StemcellVersion: dependencyVersion}
+}
+
+// TrackedDependencyVersion : the version of the dependency being tracked, which is either the dependency_version or the stemcell_version
+func (v Version) TrackedDependencyVersion() string {
+	if v.DependencyVersion != "" {
+		return v.DependencyVersion
+	}
+	return v.StemcellVersion
 }
 
 // CheckResponse : response body for the check.Command
@@ -48,10 +314,30 @@ type InRequest struct {
 	Params  InParams `json:"params"`
 }
 
+// TrackedDependencySlug : the slug of the dependency being tracked, falling back to the stemcell slug detected by check
+// when the source does not provide one
+func (r InRequest) TrackedDependencySlug() string {
+	if slug := r.Source.TrackedDependencySlug(); slug != "" {
+		return slug
+	}
+	return r.Version.StemcellSlug
+}
+
+// OutRequest : request body for the out command
+type OutRequest struct {
+	Source Source    `json:"source"`
+	Params OutParams `json:"params"`
+}
+
+// OutParams : parameter structure for information provided from Concourse on put usages, of which there are none as
+// the out command is not implemented
+type OutParams struct{}
+
 // InParams : parameter structure for information provided from Concourse on get usages
 type InParams struct {
-	Globs  []string `json:"globs"`
-	Unpack bool     `json:"unpack"`
+	Globs       []string `json:"globs"`
+	Unpack      bool     `json:"unpack"`
+	UpgradeFrom string   `json:"upgrade_from"`
 }
 
 // InResponse : response body for the in.Command
@@ -64,4 +350,4 @@ type InResponse struct {
 type Metadata struct {
 	Name  string `json:"name,omitempty"`
 	Value string `json:"value,omitempty"`
-}
\ No newline at end of file
+}
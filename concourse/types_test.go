@@ -0,0 +1,173 @@
+package concourse_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+)
+
+var _ = Describe("StringList", func() {
+	var source concourse.Source
+
+	BeforeEach(func() {
+		source = concourse.Source{}
+	})
+
+	It("accepts a single string", func() {
+		err := json.Unmarshal([]byte(`{"release_type": "Major Release", "product_version": "1\\..*"}`), &source)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(source.ReleaseType).To(Equal(concourse.StringList{"Major Release"}))
+		Expect(source.ProductVersion).To(Equal(concourse.StringList{`1\..*`}))
+	})
+
+	It("accepts a list of strings", func() {
+		err := json.Unmarshal([]byte(`{"release_type": ["Major Release", "Minor Release"]}`), &source)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(source.ReleaseType).To(Equal(concourse.StringList{"Major Release", "Minor Release"}))
+	})
+
+	It("treats an empty string as unset", func() {
+		err := json.Unmarshal([]byte(`{"release_type": ""}`), &source)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(source.ReleaseType).To(BeEmpty())
+	})
+
+	It("rejects other types", func() {
+		err := json.Unmarshal([]byte(`{"release_type": 1}`), &source)
+		Expect(err).To(HaveOccurred())
+
+		Expect(err.Error()).To(ContainSubstring("must be a string or a list of strings"))
+	})
+})
+
+var _ = Describe("InRequest", func() {
+	Describe("TrackedDependencySlug", func() {
+		It("prefers the slug provided by the source", func() {
+			inRequest := concourse.InRequest{
+				Source:  concourse.Source{StemcellSlug: "stemcells-ubuntu-xenial"},
+				Version: concourse.Version{StemcellSlug: "stemcells-ubuntu-jammy"},
+			}
+
+			Expect(inRequest.TrackedDependencySlug()).To(Equal("stemcells-ubuntu-xenial"))
+		})
+
+		It("falls back to the stemcell slug detected by check", func() {
+			inRequest := concourse.InRequest{
+				Version: concourse.Version{StemcellSlug: "stemcells-ubuntu-jammy"},
+			}
+
+			Expect(inRequest.TrackedDependencySlug()).To(Equal("stemcells-ubuntu-jammy"))
+		})
+	})
+})
+
+var _ = Describe("SortBy", func() {
+	var source concourse.Source
+
+	BeforeEach(func() {
+		source = concourse.Source{}
+	})
+
+	It("accepts every supported value", func() {
+		for _, sortBy := range concourse.SortBys {
+			err := json.Unmarshal([]byte(fmt.Sprintf(`{"sort_by": "%s"}`, sortBy)), &source)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(source.SortBy).To(Equal(sortBy))
+		}
+	})
+
+	It("accepts an empty value", func() {
+		err := json.Unmarshal([]byte(`{"sort_by": ""}`), &source)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source.SortBy).To(BeEmpty())
+	})
+
+	It("leaves unsupported values for the validator to report", func() {
+		err := json.Unmarshal([]byte(`{"sort_by": "sem_ver"}`), &source)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source.SortBy).To(Equal(concourse.SortBy("sem_ver")))
+		Expect(source.SortBy.Valid()).To(BeFalse())
+	})
+
+	It("rejects values which are not strings", func() {
+		err := json.Unmarshal([]byte(`{"sort_by": 1}`), &source)
+		Expect(err).To(MatchError("must be a string"))
+	})
+})
+
+var _ = Describe("EffectiveLogLevel", func() {
+	It("defaults to error", func() {
+		Expect(concourse.Source{}.EffectiveLogLevel()).To(Equal(concourse.LogLevelError))
+	})
+
+	It("is debug when verbose", func() {
+		Expect(concourse.Source{Verbose: true}.EffectiveLogLevel()).To(Equal(concourse.LogLevelDebug))
+	})
+
+	It("prefers the log level over verbose", func() {
+		source := concourse.Source{Verbose: true, LogLevel: concourse.LogLevelTrace}
+		Expect(source.EffectiveLogLevel()).To(Equal(concourse.LogLevelTrace))
+	})
+})
+
+var _ = Describe("ResolveAPIToken", func() {
+	It("returns the api token", func() {
+		Expect(concourse.Source{APIToken: "some-api-token"}.ResolveAPIToken()).To(Equal("some-api-token"))
+	})
+
+	Context("when the api token is provided in a file", func() {
+		var path string
+
+		BeforeEach(func() {
+			dir, err := ioutil.TempDir("", "api-token")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(os.RemoveAll, dir)
+
+			path = filepath.Join(dir, "token")
+			Expect(ioutil.WriteFile(path, []byte("some-api-token\n"), 0600)).To(Succeed())
+		})
+
+		It("reads the api token from the file", func() {
+			Expect(concourse.Source{APITokenFile: path}.ResolveAPIToken()).To(Equal("some-api-token"))
+		})
+
+		It("returns an error when the file is empty", func() {
+			Expect(ioutil.WriteFile(path, []byte("\n"), 0600)).To(Succeed())
+
+			_, err := concourse.Source{APITokenFile: path}.ResolveAPIToken()
+			Expect(err).To(MatchError(fmt.Sprintf("api_token_file is empty: '%s'", path)))
+		})
+
+		It("returns an error when the file cannot be read", func() {
+			_, err := concourse.Source{APITokenFile: path + "-missing"}.ResolveAPIToken()
+			Expect(err).To(MatchError(ContainSubstring("cannot read api_token_file")))
+		})
+	})
+
+	Context("when the api token is provided in an environment variable", func() {
+		const env = "PIVNET_PRODUCT_STEMCELL_RESOURCE_TEST_API_TOKEN"
+
+		BeforeEach(func() {
+			Expect(os.Setenv(env, "some-api-token")).To(Succeed())
+			DeferCleanup(os.Unsetenv, env)
+		})
+
+		It("reads the api token from the environment variable", func() {
+			Expect(concourse.Source{APITokenEnv: env}.ResolveAPIToken()).To(Equal("some-api-token"))
+		})
+
+		It("returns an error when the environment variable is not set", func() {
+			_, err := concourse.Source{APITokenEnv: env + "_MISSING"}.ResolveAPIToken()
+			Expect(err).To(MatchError(fmt.Sprintf("api_token_env environment variable is not set: '%s_MISSING'", env)))
+		})
+	})
+})
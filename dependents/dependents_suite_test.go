@@ -0,0 +1,13 @@
+package dependents_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDependents(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dependents Suite")
+}
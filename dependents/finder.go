@@ -0,0 +1,133 @@
+package dependents
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/pivotal-cf/go-pivnet/v7/logger"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/multifilter"
+	"github.com/shanman190/pivnet-product-stemcell-resource/versions"
+)
+
+//go:generate counterfeiter --fake-name FakeFilter . filter
+type filter interface {
+	ReleasesByReleaseType(releases []pivnet.Release, releaseType pivnet.ReleaseType) ([]pivnet.Release, error)
+	ReleasesByVersion(releases []pivnet.Release, version string) ([]pivnet.Release, error)
+}
+
+//go:generate counterfeiter --fake-name FakePivnetClient . pivnetClient
+type pivnetClient interface {
+	ReleasesForProductSlug(string) ([]pivnet.Release, error)
+	ReleaseDependencies(string, int) ([]pivnet.ReleaseDependency, error)
+}
+
+// ProductRelease : a product release which lists a dependency release
+type ProductRelease struct {
+	ProductSlug string `json:"product_slug"`
+	ID          int    `json:"id"`
+	Version     string `json:"version"`
+}
+
+// Finder : finds the product releases which depend on the releases of a dependency
+type Finder struct {
+	logger                 logger.Logger
+	filter                 filter
+	pivnetClient           pivnetClient
+	releaseDependencyCache map[int][]pivnet.ReleaseDependency
+}
+
+// NewFinder : Creates an instance of the Finder
+func NewFinder(
+	logger logger.Logger,
+	filter filter,
+	pivnetClient pivnetClient,
+) *Finder {
+	return &Finder{
+		logger:                 logger,
+		filter:                 filter,
+		pivnetClient:           pivnetClient,
+		releaseDependencyCache: make(map[int][]pivnet.ReleaseDependency),
+	}
+}
+
+// Find : lists the releases of each tracked product which depend on the tracked dependency, keyed by dependency version
+func (f *Finder) Find(source concourse.Source) (map[string][]ProductRelease, error) {
+	dependencySlug := source.TrackedDependencySlug()
+
+	multiFilter := multifilter.NewFilter(f.filter)
+
+	dependents := make(map[string][]ProductRelease)
+	for _, productSlug := range source.TrackedProductSlugs() {
+		f.logger.Info(fmt.Sprintf("Getting all '%s' releases", productSlug))
+		productReleases, err := f.pivnetClient.ReleasesForProductSlug(productSlug)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(source.ReleaseType) > 0 {
+			f.logger.Info(fmt.Sprintf("Filtering all '%s' releases by release types: ['%s']", productSlug, strings.Join(source.ReleaseType, "', '")))
+			productReleases, err = multiFilter.ReleasesByReleaseTypes(productReleases, source.ReleaseType)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(source.ProductVersion) > 0 {
+			f.logger.Info(fmt.Sprintf("Filtering all '%s' releases by product versions: ['%s']", productSlug, strings.Join(source.ProductVersion, "', '")))
+			productReleases, err = multiFilter.ReleasesByVersions(productReleases, source.ProductVersion)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if source.ProductVersionConstraint != "" {
+			f.logger.Info(fmt.Sprintf("Filtering all '%s' releases by product version constraint: '%s'", productSlug, source.ProductVersionConstraint))
+			constraint, err := versions.NewConstraint(source.ProductVersionConstraint)
+			if err != nil {
+				return nil, err
+			}
+			productReleases = constraint.Releases(productReleases)
+		}
+
+		for _, productRelease := range productReleases {
+			releaseDependencies, err := f.releaseDependencies(productSlug, productRelease)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, releaseDependency := range releaseDependencies {
+				if !strings.Contains(releaseDependency.Release.Product.Slug, dependencySlug) {
+					continue
+				}
+
+				dependencyVersion := releaseDependency.Release.Version
+				dependents[dependencyVersion] = append(dependents[dependencyVersion], ProductRelease{
+					ProductSlug: productSlug,
+					ID:          productRelease.ID,
+					Version:     productRelease.Version,
+				})
+			}
+		}
+	}
+
+	return dependents, nil
+}
+
+func (f *Finder) releaseDependencies(productSlug string, productRelease pivnet.Release) ([]pivnet.ReleaseDependency, error) {
+	releaseDependencies, ok := f.releaseDependencyCache[productRelease.ID]
+	if ok {
+		return releaseDependencies, nil
+	}
+
+	f.logger.Info(fmt.Sprintf("Getting release dependencies for '%s/%s'", productSlug, productRelease.Version))
+	releaseDependencies, err := f.pivnetClient.ReleaseDependencies(productSlug, productRelease.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	f.releaseDependencyCache[productRelease.ID] = releaseDependencies
+	return releaseDependencies, nil
+}
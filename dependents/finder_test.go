@@ -0,0 +1,170 @@
+package dependents_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/dependents"
+	"github.com/shanman190/pivnet-product-stemcell-resource/dependents/dependentsfakes"
+
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/pivotal-cf/go-pivnet/v7/logger"
+	"github.com/pivotal-cf/go-pivnet/v7/logshim"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Finder", func() {
+	var (
+		fakeLogger       logger.Logger
+		fakeFilter       *dependentsfakes.FakeFilter
+		fakePivnetClient *dependentsfakes.FakePivnetClient
+
+		source concourse.Source
+		finder *dependents.Finder
+
+		stemcellDependency = func(version string) pivnet.ReleaseDependency {
+			return pivnet.ReleaseDependency{
+				Release: pivnet.DependentRelease{
+					Version: version,
+					Product: pivnet.Product{
+						Slug: "some-stemcell",
+					},
+				},
+			}
+		}
+	)
+
+	BeforeEach(func() {
+		logger := log.New(GinkgoWriter, "", log.LstdFlags)
+		fakeLogger = logshim.NewLogShim(logger, logger, true)
+
+		fakeFilter = &dependentsfakes.FakeFilter{}
+		fakePivnetClient = &dependentsfakes.FakePivnetClient{}
+
+		source = concourse.Source{
+			ProductSlugs: []string{"product-a", "product-b"},
+			StemcellSlug: "some-stemcell",
+		}
+
+		fakePivnetClient.ReleasesForProductSlugReturnsOnCall(0, []pivnet.Release{
+			{ID: 1, Version: "1.0.0"},
+			{ID: 2, Version: "1.1.0"},
+		}, nil)
+		fakePivnetClient.ReleasesForProductSlugReturnsOnCall(1, []pivnet.Release{
+			{ID: 3, Version: "2.0.0"},
+		}, nil)
+
+		fakePivnetClient.ReleaseDependenciesReturnsOnCall(0, []pivnet.ReleaseDependency{stemcellDependency("100.1")}, nil)
+		fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, []pivnet.ReleaseDependency{stemcellDependency("100.1"), stemcellDependency("100.2")}, nil)
+		fakePivnetClient.ReleaseDependenciesReturnsOnCall(2, []pivnet.ReleaseDependency{
+			stemcellDependency("100.2"),
+			{
+				Release: pivnet.DependentRelease{
+					Version: "2.10.3",
+					Product: pivnet.Product{Slug: "ops-manager"},
+				},
+			},
+		}, nil)
+	})
+
+	JustBeforeEach(func() {
+		finder = dependents.NewFinder(fakeLogger, fakeFilter, fakePivnetClient)
+	})
+
+	It("returns the product releases depending on each dependency version", func() {
+		dependentReleases, err := finder.Find(source)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(dependentReleases).To(HaveLen(2))
+		Expect(dependentReleases["100.1"]).To(Equal([]dependents.ProductRelease{
+			{ProductSlug: "product-a", ID: 1, Version: "1.0.0"},
+			{ProductSlug: "product-a", ID: 2, Version: "1.1.0"},
+		}))
+		Expect(dependentReleases["100.2"]).To(Equal([]dependents.ProductRelease{
+			{ProductSlug: "product-a", ID: 2, Version: "1.1.0"},
+			{ProductSlug: "product-b", ID: 3, Version: "2.0.0"},
+		}))
+	})
+
+	It("caches release dependencies between finds", func() {
+		_, err := finder.Find(source)
+		Expect(err).NotTo(HaveOccurred())
+
+		fakePivnetClient.ReleasesForProductSlugReturns([]pivnet.Release{{ID: 1, Version: "1.0.0"}}, nil)
+
+		_, err = finder.Find(source)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakePivnetClient.ReleaseDependenciesCallCount()).To(Equal(3))
+	})
+
+	Context("when only a product slug is provided", func() {
+		BeforeEach(func() {
+			source.ProductSlugs = nil
+			source.ProductSlug = "product-a"
+		})
+
+		It("returns the product releases of that product", func() {
+			dependentReleases, err := finder.Find(source)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakePivnetClient.ReleasesForProductSlugCallCount()).To(Equal(1))
+			Expect(fakePivnetClient.ReleasesForProductSlugArgsForCall(0)).To(Equal("product-a"))
+			Expect(dependentReleases["100.2"]).To(Equal([]dependents.ProductRelease{
+				{ProductSlug: "product-a", ID: 2, Version: "1.1.0"},
+			}))
+		})
+	})
+
+	Context("when the release type and product version are specified", func() {
+		BeforeEach(func() {
+			source.ReleaseType = concourse.StringList{"some release type"}
+			source.ProductVersion = concourse.StringList{"1\\..*"}
+
+			fakeFilter.ReleasesByReleaseTypeReturns([]pivnet.Release{{ID: 1, Version: "1.0.0"}}, nil)
+			fakeFilter.ReleasesByVersionReturns([]pivnet.Release{{ID: 1, Version: "1.0.0"}}, nil)
+		})
+
+		It("filters the product releases", func() {
+			_, err := finder.Find(source)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeFilter.ReleasesByReleaseTypeCallCount()).To(Equal(2))
+			_, releaseType := fakeFilter.ReleasesByReleaseTypeArgsForCall(0)
+			Expect(releaseType).To(Equal(pivnet.ReleaseType("some release type")))
+
+			Expect(fakeFilter.ReleasesByVersionCallCount()).To(Equal(2))
+			_, version := fakeFilter.ReleasesByVersionArgsForCall(0)
+			Expect(version).To(Equal("1\\..*"))
+		})
+	})
+
+	Context("when getting product releases returns an error", func() {
+		BeforeEach(func() {
+			fakePivnetClient.ReleasesForProductSlugReturnsOnCall(0, nil, fmt.Errorf("some releases error"))
+		})
+
+		It("returns the error", func() {
+			_, err := finder.Find(source)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("some releases error"))
+		})
+	})
+
+	Context("when getting release dependencies returns an error", func() {
+		BeforeEach(func() {
+			fakePivnetClient.ReleaseDependenciesReturnsOnCall(1, nil, fmt.Errorf("some dependencies error"))
+		})
+
+		It("returns the error", func() {
+			_, err := finder.Find(source)
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("some dependencies error"))
+		})
+	})
+})
@@ -1,17 +1,17 @@
 module github.com/shanman190/pivnet-product-stemcell-resource
 
 require (
+	github.com/blang/semver v3.5.1+incompatible
 	github.com/fatih/color v1.13.0
 	github.com/onsi/ginkgo/v2 v2.7.0
 	github.com/onsi/gomega v1.24.2
 	github.com/pivotal-cf/go-pivnet/v7 v7.0.2
 	github.com/pivotal-cf/pivnet-resource v1.0.1
 	github.com/pivotal-cf/pivnet-resource/v3 v3.0.2
-	github.com/robdimsdale/sanitizer v0.0.0-20160522134901-ab2334cb7539
+	golang.org/x/net v0.5.0
 )
 
 require (
-	github.com/blang/semver v3.5.1+incompatible // indirect
 	github.com/go-logr/logr v1.2.3 // indirect
 	github.com/go-ole/go-ole v1.2.6 // indirect
 	github.com/google/go-cmp v0.5.9 // indirect
@@ -20,9 +20,9 @@ require (
 	github.com/mattn/go-isatty v0.0.17 // indirect
 	github.com/mattn/go-runewidth v0.0.14 // indirect
 	github.com/rivo/uniseg v0.4.3 // indirect
+	github.com/robdimsdale/sanitizer v0.0.0-20160522134901-ab2334cb7539 // indirect
 	github.com/shirou/gopsutil v3.21.11+incompatible // indirect
 	github.com/yusufpapurcu/wmi v1.2.2 // indirect
-	golang.org/x/net v0.5.0 // indirect
 	golang.org/x/sync v0.1.0 // indirect
 	golang.org/x/sys v0.4.0 // indirect
 	golang.org/x/text v0.6.0 // indirect
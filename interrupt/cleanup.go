@@ -0,0 +1,64 @@
+package interrupt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Cleanup : removes the entries created in a directory after the Cleanup, such as partially downloaded files
+type Cleanup struct {
+	dir      string
+	existing map[string]bool
+}
+
+// NewCleanup : Creates a Cleanup of the entries created in dir from now on
+func NewCleanup(dir string) (*Cleanup, error) {
+	names, err := entries(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool)
+	for _, name := range names {
+		existing[name] = true
+	}
+
+	return &Cleanup{
+		dir:      dir,
+		existing: existing,
+	}, nil
+}
+
+// Run : removes the entries created since the Cleanup was created, leaving the others in place
+func (c *Cleanup) Run() error {
+	names, err := entries(c.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if c.existing[name] {
+			continue
+		}
+
+		err = os.RemoveAll(filepath.Join(c.dir, name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func entries(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+	return names, nil
+}
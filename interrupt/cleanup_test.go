@@ -0,0 +1,45 @@
+package interrupt_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/interrupt"
+)
+
+var _ = Describe("Cleanup", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "cleanup")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "existing"), []byte("existing"), 0600)).To(Succeed())
+	})
+
+	It("removes only the entries created since it was created", func() {
+		cleanup, err := interrupt.NewCleanup(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "product.pivotal"), []byte("partial"), 0600)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(dir, "unpacked", "nested"), 0700)).To(Succeed())
+
+		Expect(cleanup.Run()).To(Succeed())
+
+		infos, err := ioutil.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(infos).To(HaveLen(1))
+		Expect(infos[0].Name()).To(Equal("existing"))
+	})
+
+	It("returns an error when the directory cannot be read", func() {
+		_, err := interrupt.NewCleanup(filepath.Join(dir, "missing"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,108 @@
+package interrupt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// TimeoutError : the context ended as the timeout elapsed
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.Timeout)
+}
+
+// SignalError : the context ended as the process received a signal
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e SignalError) Error() string {
+	return fmt.Sprintf("interrupted by signal: %s", e.Signal)
+}
+
+// Context : a context which is cancelled when the timeout elapses or the process receives one of the signals,
+// recording which of them ended it
+type Context struct {
+	context.Context
+	cancel  context.CancelFunc
+	signals chan os.Signal
+	timer   *time.Timer
+
+	mu    sync.Mutex
+	cause error
+}
+
+// NewContext : Creates a Context, which never times out when the timeout is zero. Stop must be called to release it.
+func NewContext(parent context.Context, timeout time.Duration, signals ...os.Signal) *Context {
+	ctx, cancel := context.WithCancel(parent)
+	c := &Context{
+		Context: ctx,
+		cancel:  cancel,
+		signals: make(chan os.Signal, 1),
+	}
+
+	if timeout > 0 {
+		c.timer = time.AfterFunc(timeout, func() {
+			c.end(TimeoutError{Timeout: timeout})
+		})
+	}
+
+	if len(signals) > 0 {
+		signal.Notify(c.signals, signals...)
+		go func() {
+			select {
+			case s := <-c.signals:
+				c.end(SignalError{Signal: s})
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	return c
+}
+
+// Cause : why the context ended, which is nil while it has not
+func (c *Context) Cause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cause == nil {
+		return c.Context.Err()
+	}
+	return c.cause
+}
+
+// Stop : stops listening for the signals and cancels the context
+func (c *Context) Stop() {
+	signal.Stop(c.signals)
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.cancel()
+}
+
+func (c *Context) end(cause error) {
+	c.mu.Lock()
+	if c.cause == nil {
+		c.cause = cause
+	}
+	c.mu.Unlock()
+
+	c.cancel()
+}
+
+// Cause : why the context ended, which is nil while it has not. Contexts other than a Context are described by their
+// error.
+func Cause(ctx context.Context) error {
+	if c, ok := ctx.(*Context); ok {
+		return c.Cause()
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,13 @@
+package interrupt_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestInterrupt(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Interrupt Suite")
+}
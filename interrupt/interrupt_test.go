@@ -0,0 +1,65 @@
+package interrupt_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/interrupt"
+)
+
+var _ = Describe("Context", func() {
+	It("has no cause while it has not ended", func() {
+		ctx := interrupt.NewContext(context.Background(), 0)
+		defer ctx.Stop()
+
+		Expect(ctx.Err()).NotTo(HaveOccurred())
+		Expect(ctx.Cause()).NotTo(HaveOccurred())
+	})
+
+	It("ends when the timeout elapses", func() {
+		ctx := interrupt.NewContext(context.Background(), time.Millisecond)
+		defer ctx.Stop()
+
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(ctx.Cause()).To(MatchError("timed out after 1ms"))
+		Expect(errors.Is(ctx.Cause(), interrupt.TimeoutError{Timeout: time.Millisecond})).To(BeTrue())
+	})
+
+	It("ends when the process receives one of the signals", func() {
+		ctx := interrupt.NewContext(context.Background(), 0, syscall.SIGUSR1)
+		defer ctx.Stop()
+
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR1)).To(Succeed())
+
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(ctx.Cause()).To(MatchError("interrupted by signal: user defined signal 1"))
+
+		var signalErr interrupt.SignalError
+		Expect(errors.As(ctx.Cause(), &signalErr)).To(BeTrue())
+		Expect(signalErr.Signal).To(Equal(syscall.SIGUSR1))
+	})
+
+	It("is canceled when stopped", func() {
+		ctx := interrupt.NewContext(context.Background(), time.Hour)
+		ctx.Stop()
+
+		Expect(ctx.Done()).To(BeClosed())
+		Expect(ctx.Cause()).To(MatchError(context.Canceled))
+	})
+
+	Describe("Cause", func() {
+		It("describes other contexts by their error", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			Expect(interrupt.Cause(ctx)).NotTo(HaveOccurred())
+
+			cancel()
+			Expect(interrupt.Cause(ctx)).To(MatchError(context.Canceled))
+		})
+	})
+})
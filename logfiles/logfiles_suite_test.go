@@ -0,0 +1,13 @@
+package logfiles_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLogfiles(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Logfiles Suite")
+}
@@ -0,0 +1,174 @@
+package logfiles
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+)
+
+const (
+	// MaxAge : how long the log files of a resource are kept for
+	MaxAge = 7 * 24 * time.Hour
+	// MaxTotalSize : how many bytes the log files of a resource may take up, beyond which the oldest are removed
+	MaxTotalSize = 50 << 20
+
+	filePrefix    = "check-"
+	fileSuffix    = ".log"
+	timeLayout    = "20060102T150405.000000000"
+	dirHashLength = 12
+)
+
+var unsafeDirChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+//go:generate counterfeiter --fake-name FakeClock . clock
+type clock interface {
+	Now() time.Time
+}
+
+// DefaultRoot : the directory holding the log directories of every resource
+func DefaultRoot() string {
+	return filepath.Join(os.TempDir(), "pivnet-product-stemcell-resource", "logs")
+}
+
+// Dir : the log directory of the resource configured by source, which is shared by every check of that resource on
+// the worker and by nothing else
+func Dir(root string, source concourse.Source) string {
+	productSlugs := source.TrackedProductSlugs()
+
+	identity := strings.Join([]string{
+		source.Endpoint,
+		string(source.Mode),
+		strings.Join(productSlugs, ","),
+		source.StemcellSlug,
+		source.DependencySlug,
+	}, "\x00")
+	hash := sha256.Sum256([]byte(identity))
+
+	name := hex.EncodeToString(hash[:])[:dirHashLength]
+	if len(productSlugs) > 0 {
+		name = unsafeDirChars.ReplaceAllString(productSlugs[0], "_") + "-" + name
+	}
+	return filepath.Join(root, name)
+}
+
+// Rotator : creates a log file in the log directory of a resource for each check, removing the oldest log files of
+// the resource once there are more than the retention, they are older than the max age or they take up more than the
+// max total size
+type Rotator struct {
+	dir          string
+	retention    int
+	maxAge       time.Duration
+	maxTotalSize int64
+	clock        clock
+}
+
+// NewRotator : Creates an instance of the Rotator of the log files in dir, where a retention below one keeps
+// concourse.DefaultLogRetention log files
+func NewRotator(dir string, retention int, maxAge time.Duration, maxTotalSize int64, clock clock) *Rotator {
+	if retention < 1 {
+		retention = concourse.DefaultLogRetention
+	}
+
+	return &Rotator{
+		dir:          dir,
+		retention:    retention,
+		maxAge:       maxAge,
+		maxTotalSize: maxTotalSize,
+		clock:        clock,
+	}
+}
+
+// Create : creates a new log file, named after the current time so that log files sort from oldest to newest
+func (r *Rotator) Create() (*os.File, error) {
+	err := os.MkdirAll(r.dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s%s-%d%s", filePrefix, r.clock.Now().UTC().Format(timeLayout), os.Getpid(), fileSuffix)
+	return os.OpenFile(filepath.Join(r.dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+}
+
+// Prune : removes the log files beyond the retention, max age or max total size, newest first, other than current.
+// Only regular files named like the log files created by the Rotator are ever removed.
+func (r *Rotator) Prune(current string) error {
+	logFiles, err := r.logFiles()
+	if err != nil {
+		return err
+	}
+
+	now := r.clock.Now()
+	kept := 0
+	var totalSize int64
+
+	for _, logFile := range logFiles {
+		if logFile.path == filepath.Clean(current) {
+			kept++
+			totalSize += logFile.size
+			continue
+		}
+
+		if kept < r.retention && totalSize+logFile.size <= r.maxTotalSize && now.Sub(logFile.modTime) <= r.maxAge {
+			kept++
+			totalSize += logFile.size
+			continue
+		}
+
+		err := os.Remove(logFile.path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type logFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// logFiles lists the log files in the directory from newest to oldest
+func (r *Rotator) logFiles() ([]logFile, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var logFiles []logFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if os.IsNotExist(err) {
+			// removed by a concurrent check
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		logFiles = append(logFiles, logFile{
+			path:    filepath.Join(r.dir, name),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+
+	sort.SliceStable(logFiles, func(i, j int) bool {
+		return logFiles[i].path > logFiles[j].path
+	})
+	return logFiles, nil
+}
@@ -0,0 +1,287 @@
+package logfiles_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/logfiles"
+	"github.com/shanman190/pivnet-product-stemcell-resource/logfiles/logfilesfakes"
+)
+
+var _ = Describe("Dir", func() {
+	var source concourse.Source
+
+	BeforeEach(func() {
+		source = concourse.Source{
+			ProductSlug:  "some-product",
+			StemcellSlug: "stemcells-ubuntu-jammy",
+		}
+	})
+
+	It("is named after the product slug within the root", func() {
+		dir := logfiles.Dir("/some/root", source)
+		Expect(filepath.Dir(dir)).To(Equal("/some/root"))
+		Expect(filepath.Base(dir)).To(HavePrefix("some-product-"))
+	})
+
+	It("is the same for the same resource", func() {
+		other := source
+		other.APIToken = "some-other-api-token"
+		other.ProductVersion = concourse.StringList{"1\\..*"}
+
+		Expect(logfiles.Dir("/some/root", other)).To(Equal(logfiles.Dir("/some/root", source)))
+	})
+
+	It("differs between resources", func() {
+		other := source
+		other.StemcellSlug = "stemcells-windows-server"
+
+		Expect(logfiles.Dir("/some/root", other)).NotTo(Equal(logfiles.Dir("/some/root", source)))
+	})
+
+	Context("when the product slug contains path separators", func() {
+		BeforeEach(func() {
+			source.ProductSlug = "../../etc"
+		})
+
+		It("stays within the root", func() {
+			dir := logfiles.Dir("/some/root", source)
+			Expect(filepath.Dir(dir)).To(Equal("/some/root"))
+			Expect(filepath.Base(dir)).NotTo(ContainSubstring("/"))
+		})
+	})
+})
+
+var _ = Describe("Rotator", func() {
+	var (
+		tempDir   string
+		dir       string
+		fakeClock *logfilesfakes.FakeClock
+		now       time.Time
+
+		retention    int
+		maxTotalSize int64
+
+		rotator *logfiles.Rotator
+	)
+
+	writeLogFile := func(name string, age time.Duration, content string) string {
+		path := filepath.Join(dir, name)
+		err := ioutil.WriteFile(path, []byte(content), 0600)
+		Expect(err).NotTo(HaveOccurred())
+
+		modTime := now.Add(-age)
+		err = os.Chtimes(path, modTime, modTime)
+		Expect(err).NotTo(HaveOccurred())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "logfiles")
+		Expect(err).NotTo(HaveOccurred())
+
+		dir = filepath.Join(tempDir, "some-resource")
+		err = os.MkdirAll(dir, 0700)
+		Expect(err).NotTo(HaveOccurred())
+
+		now = time.Now()
+		fakeClock = &logfilesfakes.FakeClock{}
+		fakeClock.NowReturns(now)
+
+		retention = 2
+		maxTotalSize = 1 << 20
+	})
+
+	JustBeforeEach(func() {
+		rotator = logfiles.NewRotator(dir, retention, 24*time.Hour, maxTotalSize, fakeClock)
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(tempDir)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Create", func() {
+		It("creates a log file only readable by the owner", func() {
+			logFile, err := rotator.Create()
+			Expect(err).NotTo(HaveOccurred())
+			defer logFile.Close()
+
+			Expect(filepath.Dir(logFile.Name())).To(Equal(dir))
+			Expect(filepath.Base(logFile.Name())).To(HavePrefix("check-"))
+			Expect(filepath.Base(logFile.Name())).To(HaveSuffix(".log"))
+
+			info, err := os.Stat(logFile.Name())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("names log files so that they sort from oldest to newest", func() {
+			first, err := rotator.Create()
+			Expect(err).NotTo(HaveOccurred())
+			defer first.Close()
+
+			fakeClock.NowReturns(now.Add(time.Second))
+			second, err := rotator.Create()
+			Expect(err).NotTo(HaveOccurred())
+			defer second.Close()
+
+			Expect(strings.Compare(first.Name(), second.Name())).To(Equal(-1))
+		})
+
+		Context("when the directory does not exist", func() {
+			BeforeEach(func() {
+				dir = filepath.Join(tempDir, "some-missing-resource")
+			})
+
+			It("creates it only accessible by the owner", func() {
+				logFile, err := rotator.Create()
+				Expect(err).NotTo(HaveOccurred())
+				defer logFile.Close()
+
+				info, err := os.Stat(dir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0700)))
+			})
+		})
+	})
+
+	Describe("Prune", func() {
+		var (
+			current string
+			older   string
+			oldest  string
+		)
+
+		BeforeEach(func() {
+			oldest = writeLogFile("check-20240101T000000.000000000-1.log", 3*time.Hour, "oldest")
+			older = writeLogFile("check-20240101T010000.000000000-1.log", 2*time.Hour, "older")
+			current = writeLogFile("check-20240101T020000.000000000-1.log", time.Hour, "current")
+		})
+
+		It("keeps the newest log files within the retention", func() {
+			err := rotator.Prune(current)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(current).To(BeAnExistingFile())
+			Expect(older).To(BeAnExistingFile())
+			Expect(oldest).NotTo(BeAnExistingFile())
+		})
+
+		It("leaves every other file alone", func() {
+			others := []string{
+				writeLogFile("pivnet-check.log1234", 48*time.Hour, "some other log"),
+				writeLogFile("check-notes.txt", 48*time.Hour, "some notes"),
+				writeLogFile("in-20240101T000000.000000000-1.log", 48*time.Hour, "some get log"),
+			}
+
+			err := os.Mkdir(filepath.Join(dir, "check-directory.log"), 0700)
+			Expect(err).NotTo(HaveOccurred())
+			others = append(others, filepath.Join(dir, "check-directory.log"))
+
+			err = rotator.Prune(current)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, other := range others {
+				_, err := os.Stat(other)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("leaves the files outside of the directory alone", func() {
+			outside := filepath.Join(tempDir, "check-20240101T000000.000000000-1.log")
+			err := ioutil.WriteFile(outside, []byte("some other resource"), 0600)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = rotator.Prune(current)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(outside).To(BeAnExistingFile())
+		})
+
+		Context("when a log file is older than the max age", func() {
+			BeforeEach(func() {
+				older = writeLogFile("check-20240101T010000.000000000-1.log", 25*time.Hour, "older")
+			})
+
+			It("removes it", func() {
+				err := rotator.Prune(current)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(current).To(BeAnExistingFile())
+				Expect(older).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when the log files are larger than the max total size", func() {
+			BeforeEach(func() {
+				maxTotalSize = int64(len("current") + len("older") - 1)
+			})
+
+			It("removes the oldest ones", func() {
+				err := rotator.Prune(current)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(current).To(BeAnExistingFile())
+				Expect(older).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when the current log file is larger than the max total size", func() {
+			BeforeEach(func() {
+				maxTotalSize = 1
+			})
+
+			It("keeps it", func() {
+				err := rotator.Prune(current)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(current).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when a concurrent check has created a newer log file", func() {
+			var newer string
+
+			BeforeEach(func() {
+				newer = writeLogFile("check-20240101T030000.000000000-2.log", 0, "newer")
+			})
+
+			It("keeps both", func() {
+				err := rotator.Prune(current)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(newer).To(BeAnExistingFile())
+				Expect(current).To(BeAnExistingFile())
+				Expect(older).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when the retention is not provided", func() {
+			BeforeEach(func() {
+				retention = 0
+				for i := 0; i < concourse.DefaultLogRetention; i++ {
+					writeLogFile("check-20231231T00000"+string(rune('0'+i))+".000000000-1.log", 4*time.Hour, "older")
+				}
+			})
+
+			It("keeps the default number of log files", func() {
+				err := rotator.Prune(current)
+				Expect(err).NotTo(HaveOccurred())
+
+				logFiles, err := filepath.Glob(filepath.Join(dir, "check-*.log"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(logFiles).To(HaveLen(concourse.DefaultLogRetention))
+				Expect(current).To(BeAnExistingFile())
+			})
+		})
+	})
+})
@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/v7/logger"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+)
+
+// Logger : logger.Logger writing the messages at info, or at the level when it is more verbose, to the log file and
+// mirroring the messages at the level to stderr
+type Logger struct {
+	file   *log.Logger
+	stderr *log.Logger
+	level  int
+}
+
+// NewLogger : Creates an instance of the Logger writing to file and stderr
+func NewLogger(file *log.Logger, stderr *log.Logger, level concourse.LogLevel) *Logger {
+	return &Logger{
+		file:   file,
+		stderr: stderr,
+		level:  rank(level),
+	}
+}
+
+// Error : logs a message at the error level
+func (l *Logger) Error(action string, data ...logger.Data) {
+	l.log(concourse.LogLevelError, action, data)
+}
+
+// Info : logs a message at the info level
+func (l *Logger) Info(action string, data ...logger.Data) {
+	l.log(concourse.LogLevelInfo, action, data)
+}
+
+// Debug : logs a message at the debug level
+func (l *Logger) Debug(action string, data ...logger.Data) {
+	l.log(concourse.LogLevelDebug, action, data)
+}
+
+// Trace : logs a message at the trace level
+func (l *Logger) Trace(action string, data ...logger.Data) {
+	l.log(concourse.LogLevelTrace, action, data)
+}
+
+// Enabled : whether messages at the level are logged anywhere
+func (l *Logger) Enabled(level concourse.LogLevel) bool {
+	return rank(level) <= l.fileLevel()
+}
+
+// Transport : wraps rt to trace a summary of every request and its response, returning rt as is unless tracing
+func (l *Logger) Transport(rt http.RoundTripper) http.RoundTripper {
+	if !l.Enabled(concourse.LogLevelTrace) {
+		return rt
+	}
+	return tracingTransport{logger: l, rt: rt}
+}
+
+func (l *Logger) log(level concourse.LogLevel, action string, data []logger.Data) {
+	message := fmt.Sprintf("%s%s", action, appendString(data))
+
+	if rank(level) <= l.fileLevel() {
+		l.file.Println(message)
+	}
+	if rank(level) <= l.level {
+		l.stderr.Println(message)
+	}
+}
+
+// fileLevel is the rank of the messages written to the log file, which always include the progress of a check
+func (l *Logger) fileLevel() int {
+	if info := rank(concourse.LogLevelInfo); l.level < info {
+		return info
+	}
+	return l.level
+}
+
+// rank is the verbosity of the level, where unsupported levels are treated as error
+func rank(level concourse.LogLevel) int {
+	for i, l := range concourse.LogLevels {
+		if level == l {
+			return i
+		}
+	}
+	return 0
+}
+
+// appendString formats data the same as the logshim of go-pivnet
+func appendString(data []logger.Data) string {
+	if len(data) > 0 {
+		return fmt.Sprintf(" - %+v", data)
+	}
+	return ""
+}
+
+type tracingTransport struct {
+	logger *Logger
+	rt     http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.logger.Trace("PivNet request", logger.Data{
+		"method": req.Method,
+		"url":    req.URL.String(),
+	})
+
+	start := time.Now()
+	resp, err := t.rt.RoundTrip(req)
+	duration := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		t.logger.Trace("PivNet request failed", logger.Data{
+			"method":   req.Method,
+			"url":      req.URL.String(),
+			"duration": duration.String(),
+			"error":    err.Error(),
+		})
+		return nil, err
+	}
+
+	t.logger.Trace("PivNet response", logger.Data{
+		"method":         req.Method,
+		"url":            req.URL.String(),
+		"status":         resp.Status,
+		"content_length": resp.ContentLength,
+		"duration":       duration.String(),
+	})
+	return resp, nil
+}
@@ -0,0 +1,160 @@
+package logging_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet/v7/logger"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/logging"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+var _ = Describe("Logger", func() {
+	var (
+		file   *bytes.Buffer
+		stderr *bytes.Buffer
+		level  concourse.LogLevel
+
+		l *logging.Logger
+	)
+
+	logEveryLevel := func() {
+		l.Error("some error")
+		l.Info("some info", logger.Data{"some": "data"})
+		l.Debug("some debug")
+		l.Trace("some trace")
+	}
+
+	BeforeEach(func() {
+		file = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+		level = concourse.LogLevelError
+	})
+
+	JustBeforeEach(func() {
+		l = logging.NewLogger(log.New(file, "", 0), log.New(stderr, "", 0), level)
+	})
+
+	It("formats messages the same as the go-pivnet logshim", func() {
+		l.Info("some info", logger.Data{"some": "data"})
+		Expect(file.String()).To(Equal("some info - [map[some:data]]\n"))
+	})
+
+	Context("when the level is error", func() {
+		It("writes info to the log file and only errors to stderr", func() {
+			logEveryLevel()
+
+			Expect(file.String()).To(Equal("some error\nsome info - [map[some:data]]\n"))
+			Expect(stderr.String()).To(Equal("some error\n"))
+		})
+	})
+
+	Context("when the level is info", func() {
+		BeforeEach(func() {
+			level = concourse.LogLevelInfo
+		})
+
+		It("mirrors info to stderr", func() {
+			logEveryLevel()
+
+			Expect(file.String()).To(Equal("some error\nsome info - [map[some:data]]\n"))
+			Expect(stderr.String()).To(Equal(file.String()))
+		})
+	})
+
+	Context("when the level is debug", func() {
+		BeforeEach(func() {
+			level = concourse.LogLevelDebug
+		})
+
+		It("writes debug to the log file and stderr", func() {
+			logEveryLevel()
+
+			Expect(file.String()).To(Equal("some error\nsome info - [map[some:data]]\nsome debug\n"))
+			Expect(stderr.String()).To(Equal(file.String()))
+		})
+	})
+
+	Context("when the level is trace", func() {
+		BeforeEach(func() {
+			level = concourse.LogLevelTrace
+		})
+
+		It("writes every message to the log file and stderr", func() {
+			logEveryLevel()
+
+			Expect(file.String()).To(Equal("some error\nsome info - [map[some:data]]\nsome debug\nsome trace\n"))
+			Expect(stderr.String()).To(Equal(file.String()))
+		})
+	})
+
+	Describe("Enabled", func() {
+		It("is whether messages at the level are logged anywhere", func() {
+			Expect(l.Enabled(concourse.LogLevelInfo)).To(BeTrue())
+			Expect(l.Enabled(concourse.LogLevelDebug)).To(BeFalse())
+		})
+	})
+
+	Describe("Transport", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+				_, _ = w.Write([]byte("some body"))
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		Context("when the level is not trace", func() {
+			It("returns the round tripper as is", func() {
+				Expect(l.Transport(http.DefaultTransport)).To(BeIdenticalTo(http.DefaultTransport))
+			})
+		})
+
+		Context("when the level is trace", func() {
+			BeforeEach(func() {
+				level = concourse.LogLevelTrace
+			})
+
+			It("traces a summary of the request and its response", func() {
+				client := &http.Client{Transport: l.Transport(http.DefaultTransport)}
+				resp, err := client.Get(server.URL + "/api/v2/products")
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+
+				Expect(stderr.String()).To(ContainSubstring("PivNet request - [map[method:GET url:" + server.URL + "/api/v2/products]]"))
+				Expect(stderr.String()).To(MatchRegexp(`PivNet response - \[map\[content_length:9 duration:\S+ method:GET status:418 I'm a teapot url:` + server.URL + `/api/v2/products\]\]`))
+			})
+
+			It("traces failed requests", func() {
+				t := l.Transport(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+					return nil, errors.New("some request error")
+				}))
+
+				req, err := http.NewRequest("GET", server.URL+"/api/v2/products", nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = t.RoundTrip(req)
+				Expect(err).To(MatchError("some request error"))
+
+				Expect(stderr.String()).To(MatchRegexp(`PivNet request failed - \[map\[duration:\S+ error:some request error method:GET url:` + server.URL + `/api/v2/products\]\]`))
+			})
+		})
+	})
+})
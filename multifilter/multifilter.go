@@ -0,0 +1,68 @@
+package multifilter
+
+import (
+	"github.com/pivotal-cf/go-pivnet/v7"
+)
+
+//go:generate counterfeiter --fake-name FakeFilter . filter
+type filter interface {
+	ReleasesByReleaseType(releases []pivnet.Release, releaseType pivnet.ReleaseType) ([]pivnet.Release, error)
+	ReleasesByVersion(releases []pivnet.Release, version string) ([]pivnet.Release, error)
+}
+
+// Filter : applies a filter once for each of several release types or versions, keeping releases matching any of them
+type Filter struct {
+	filter filter
+}
+
+// NewFilter : Creates an instance of the Filter
+func NewFilter(filter filter) *Filter {
+	return &Filter{
+		filter: filter,
+	}
+}
+
+// ReleasesByReleaseTypes : returns the releases having any of the release types, in their original order
+func (f Filter) ReleasesByReleaseTypes(releases []pivnet.Release, releaseTypes []string) ([]pivnet.Release, error) {
+	var matches [][]pivnet.Release
+	for _, releaseType := range releaseTypes {
+		m, err := f.filter.ReleasesByReleaseType(releases, pivnet.ReleaseType(releaseType))
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+
+	return union(releases, matches), nil
+}
+
+// ReleasesByVersions : returns the releases matching any of the version regular expressions, in their original order
+func (f Filter) ReleasesByVersions(releases []pivnet.Release, versions []string) ([]pivnet.Release, error) {
+	var matches [][]pivnet.Release
+	for _, version := range versions {
+		m, err := f.filter.ReleasesByVersion(releases, version)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+
+	return union(releases, matches), nil
+}
+
+func union(releases []pivnet.Release, matches [][]pivnet.Release) []pivnet.Release {
+	matched := make(map[int]bool)
+	for _, m := range matches {
+		for _, release := range m {
+			matched[release.ID] = true
+		}
+	}
+
+	var unioned []pivnet.Release
+	for _, release := range releases {
+		if matched[release.ID] {
+			unioned = append(unioned, release)
+		}
+	}
+	return unioned
+}
@@ -0,0 +1,13 @@
+package multifilter_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestMultifilter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Multifilter Suite")
+}
@@ -0,0 +1,113 @@
+package multifilter_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/shanman190/pivnet-product-stemcell-resource/multifilter"
+	"github.com/shanman190/pivnet-product-stemcell-resource/multifilter/multifilterfakes"
+)
+
+var _ = Describe("Filter", func() {
+	var (
+		fakeFilter *multifilterfakes.FakeFilter
+		releases   []pivnet.Release
+
+		f *multifilter.Filter
+	)
+
+	BeforeEach(func() {
+		fakeFilter = &multifilterfakes.FakeFilter{}
+
+		releases = []pivnet.Release{
+			{ID: 1, Version: "2.0.0", ReleaseType: "Major Release"},
+			{ID: 2, Version: "1.1.0", ReleaseType: "Minor Release"},
+			{ID: 3, Version: "1.0.1", ReleaseType: "Security Release"},
+		}
+
+		f = multifilter.NewFilter(fakeFilter)
+	})
+
+	Describe("ReleasesByReleaseTypes", func() {
+		BeforeEach(func() {
+			fakeFilter.ReleasesByReleaseTypeReturnsOnCall(0, []pivnet.Release{releases[2]}, nil)
+			fakeFilter.ReleasesByReleaseTypeReturnsOnCall(1, []pivnet.Release{releases[0]}, nil)
+		})
+
+		It("returns the releases matching any release type in their original order", func() {
+			filtered, err := f.ReleasesByReleaseTypes(releases, []string{"Security Release", "Major Release"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filtered).To(Equal([]pivnet.Release{releases[0], releases[2]}))
+
+			Expect(fakeFilter.ReleasesByReleaseTypeCallCount()).To(Equal(2))
+			_, releaseType := fakeFilter.ReleasesByReleaseTypeArgsForCall(0)
+			Expect(releaseType).To(Equal(pivnet.ReleaseType("Security Release")))
+			_, releaseType = fakeFilter.ReleasesByReleaseTypeArgsForCall(1)
+			Expect(releaseType).To(Equal(pivnet.ReleaseType("Major Release")))
+		})
+
+		Context("when filtering returns an error", func() {
+			var filterErr error
+
+			BeforeEach(func() {
+				filterErr = fmt.Errorf("some release type error")
+				fakeFilter.ReleasesByReleaseTypeReturnsOnCall(1, nil, filterErr)
+			})
+
+			It("returns the error", func() {
+				_, err := f.ReleasesByReleaseTypes(releases, []string{"Security Release", "Major Release"})
+				Expect(err).To(Equal(filterErr))
+			})
+		})
+	})
+
+	Describe("ReleasesByVersions", func() {
+		BeforeEach(func() {
+			fakeFilter.ReleasesByVersionReturnsOnCall(0, []pivnet.Release{releases[1], releases[2]}, nil)
+			fakeFilter.ReleasesByVersionReturnsOnCall(1, []pivnet.Release{releases[2]}, nil)
+		})
+
+		It("returns the releases matching any version without duplicates", func() {
+			filtered, err := f.ReleasesByVersions(releases, []string{`1\..*`, `1\.0\..*`})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filtered).To(Equal([]pivnet.Release{releases[1], releases[2]}))
+
+			Expect(fakeFilter.ReleasesByVersionCallCount()).To(Equal(2))
+			_, version := fakeFilter.ReleasesByVersionArgsForCall(0)
+			Expect(version).To(Equal(`1\..*`))
+			_, version = fakeFilter.ReleasesByVersionArgsForCall(1)
+			Expect(version).To(Equal(`1\.0\..*`))
+		})
+
+		Context("when no release matches", func() {
+			BeforeEach(func() {
+				fakeFilter.ReleasesByVersionReturnsOnCall(0, nil, nil)
+			})
+
+			It("returns no releases", func() {
+				filtered, err := f.ReleasesByVersions(releases, []string{`3\..*`})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(filtered).To(BeEmpty())
+			})
+		})
+
+		Context("when filtering returns an error", func() {
+			var filterErr error
+
+			BeforeEach(func() {
+				filterErr = fmt.Errorf("some version error")
+				fakeFilter.ReleasesByVersionReturnsOnCall(0, nil, filterErr)
+			})
+
+			It("returns the error", func() {
+				_, err := f.ReleasesByVersions(releases, []string{`1\..*`})
+				Expect(err).To(Equal(filterErr))
+			})
+		})
+	})
+})
@@ -0,0 +1,80 @@
+package pivnetclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/auth"
+)
+
+const (
+	accessTokensPath   = "/api/v2/authentication/access_tokens"
+	accessTokenTimeout = 60 * time.Second
+)
+
+// AccessTokenOrLegacyToken : exchanges a UAA refresh token for an access token over the given transport, or returns
+// a legacy API token as is. It mirrors pivnet.AccessTokenOrLegacyToken, which can only skip SSL validation.
+type AccessTokenOrLegacyToken struct {
+	token     string
+	host      string
+	userAgent string
+	client    *http.Client
+}
+
+// NewAccessTokenOrLegacyToken : Creates an instance of the AccessTokenOrLegacyToken
+func NewAccessTokenOrLegacyToken(token string, host string, userAgent string, transport http.RoundTripper) AccessTokenOrLegacyToken {
+	return AccessTokenOrLegacyToken{
+		token:     token,
+		host:      host,
+		userAgent: userAgent,
+		client: &http.Client{
+			Timeout:   accessTokenTimeout,
+			Transport: transport,
+		},
+	}
+}
+
+// AccessToken : the token to authenticate requests to Pivotal Network with
+func (t AccessTokenOrLegacyToken) AccessToken() (string, error) {
+	if auth.IsLegacyToken(t.token) {
+		return t.token, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"refresh_token": t.token})
+	if err != nil {
+		// Untested as it is too hard to force json.Marshal to return an error
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.host+accessTokensPath, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to construct API token request: %s", err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	if t.userAgent != "" {
+		req.Header.Add("User-Agent", t.userAgent)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("API token request failed: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch API token - received status %v", resp.StatusCode)
+	}
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode API token response: %s", err)
+	}
+
+	return response.AccessToken, nil
+}
@@ -0,0 +1,168 @@
+package pivnetclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/pivotal-cf/go-pivnet/v7/download"
+	"github.com/pivotal-cf/go-pivnet/v7/logger"
+)
+
+const (
+	// concurrentDownloads and downloadTimeout are those go-pivnet downloads with
+	concurrentDownloads = 10
+	downloadTimeout     = 30 * time.Second
+)
+
+// Client : the calls to Pivotal Network the resource makes, including downloads. It provides the calls of the
+// pivnet-resource client without wrapping it, as that client does not allow its transport to be replaced.
+type Client struct {
+	pivnet     pivnet.Client
+	downloader download.Client
+	logger     logger.Logger
+}
+
+// NewClient : Creates an instance of the Client, making every request, including downloads, over the transport. The
+// transports go-pivnet builds are used when t is nil.
+func NewClient(token pivnet.AccessTokenService, config pivnet.ClientConfig, t http.RoundTripper, logger logger.Logger) *Client {
+	c := pivnet.NewClient(token, config, logger)
+	if t != nil {
+		// HTTP is shared by every service of the client
+		c.HTTP.Transport = t
+	} else {
+		t = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: config.SkipSSLValidation,
+			},
+			Proxy: http.ProxyFromEnvironment,
+		}
+	}
+
+	return &Client{
+		pivnet: c,
+		downloader: download.Client{
+			HTTPClient: &http.Client{Transport: t},
+			Ranger:     download.NewRanger(concurrentDownloads),
+			Logger:     logger,
+			Timeout:    downloadTimeout,
+		},
+		logger: logger,
+	}
+}
+
+// Products : lists all of the products on Pivotal Network
+func (c Client) Products() ([]pivnet.Product, error) {
+	return c.pivnet.Products.List()
+}
+
+// FindProductForSlug : gets the product of the slug
+func (c Client) FindProductForSlug(slug string) (pivnet.Product, error) {
+	return c.pivnet.Products.Get(slug)
+}
+
+// ReleaseTypes : lists the release types releases may have
+func (c Client) ReleaseTypes() ([]pivnet.ReleaseType, error) {
+	return c.pivnet.ReleaseTypes.Get()
+}
+
+// ReleasesForProductSlug : lists the releases of the product
+func (c Client) ReleasesForProductSlug(productSlug string) ([]pivnet.Release, error) {
+	return c.pivnet.Releases.List(productSlug)
+}
+
+// GetRelease : gets the release of the product with the version
+func (c Client) GetRelease(productSlug string, version string) (pivnet.Release, error) {
+	releases, err := c.pivnet.Releases.List(productSlug)
+	if err != nil {
+		return pivnet.Release{}, err
+	}
+
+	for _, release := range releases {
+		if release.Version == version {
+			return c.pivnet.Releases.Get(productSlug, release.ID)
+		}
+	}
+
+	return pivnet.Release{}, fmt.Errorf("release not found for '%s/%s'", productSlug, version)
+}
+
+// AcceptEULA : accepts the EULA of the release
+func (c Client) AcceptEULA(productSlug string, releaseID int) error {
+	return c.pivnet.EULA.Accept(productSlug, releaseID)
+}
+
+// ProductFilesForRelease : lists the product files of the release
+func (c Client) ProductFilesForRelease(productSlug string, releaseID int) ([]pivnet.ProductFile, error) {
+	return c.pivnet.ProductFiles.ListForRelease(productSlug, releaseID)
+}
+
+// ProductFileForRelease : gets a product file of the release
+func (c Client) ProductFileForRelease(productSlug string, releaseID int, productFileID int) (pivnet.ProductFile, error) {
+	return c.pivnet.ProductFiles.GetForRelease(productSlug, releaseID, productFileID)
+}
+
+// FileGroupsForRelease : lists the file groups of the release
+func (c Client) FileGroupsForRelease(productSlug string, releaseID int) ([]pivnet.FileGroup, error) {
+	return c.pivnet.FileGroups.ListForRelease(productSlug, releaseID)
+}
+
+// ArtifactReferencesForRelease : lists the artifact references of the release
+func (c Client) ArtifactReferencesForRelease(productSlug string, releaseID int) ([]pivnet.ArtifactReference, error) {
+	return c.pivnet.ArtifactReferences.ListForRelease(productSlug, releaseID)
+}
+
+// ReleaseDependencies : lists the releases the release depends on
+func (c Client) ReleaseDependencies(productSlug string, releaseID int) ([]pivnet.ReleaseDependency, error) {
+	return c.pivnet.ReleaseDependencies.List(productSlug, releaseID)
+}
+
+// DependencySpecifiers : lists the dependency specifiers of the release
+func (c Client) DependencySpecifiers(productSlug string, releaseID int) ([]pivnet.DependencySpecifier, error) {
+	return c.pivnet.DependencySpecifiers.List(productSlug, releaseID)
+}
+
+// ReleaseUpgradePaths : lists the releases which may be upgraded to the release
+func (c Client) ReleaseUpgradePaths(productSlug string, releaseID int) ([]pivnet.ReleaseUpgradePath, error) {
+	return c.pivnet.ReleaseUpgradePaths.Get(productSlug, releaseID)
+}
+
+// UpgradePathSpecifiers : lists the upgrade path specifiers of the release
+func (c Client) UpgradePathSpecifiers(productSlug string, releaseID int) ([]pivnet.UpgradePathSpecifier, error) {
+	return c.pivnet.UpgradePathSpecifiers.List(productSlug, releaseID)
+}
+
+// DownloadProductFile : downloads a product file of the release to location, the same as go-pivnet other than using
+// the download client of this Client
+func (c Client) DownloadProductFile(
+	location *download.FileInfo,
+	productSlug string,
+	releaseID int,
+	productFileID int,
+	progressWriter io.Writer,
+) error {
+	productFile, err := c.pivnet.ProductFiles.GetForRelease(productSlug, releaseID, productFileID)
+	if err != nil {
+		return fmt.Errorf("GetForRelease: %s", err)
+	}
+
+	downloadLink, err := productFile.DownloadLink()
+	if err != nil {
+		return fmt.Errorf("DownloadLink: %s", err)
+	}
+
+	c.logger.Debug("Downloading file", logger.Data{"downloadLink": downloadLink})
+
+	downloader := c.downloader
+	downloader.Bar = download.NewBar()
+
+	err = downloader.Get(location, pivnet.NewProductFileLinkFetcher(downloadLink, c.pivnet), progressWriter)
+	if err != nil {
+		return fmt.Errorf("Downloader.Get: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package pivnetclient_test
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/pivotal-cf/go-pivnet/v7/download"
+	"github.com/pivotal-cf/go-pivnet/v7/logshim"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/pivnetclient"
+	"github.com/shanman190/pivnet-product-stemcell-resource/transport"
+)
+
+const (
+	refreshToken = "some-uaa-refresh-token-longer-than-a-legacy-token"
+	fileContent  = "some product file content which is downloaded in several ranges"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		handler   http.HandlerFunc
+		serverURL string
+		ls        *logshim.LogShim
+
+		authorizationHeaders []string
+	)
+
+	BeforeEach(func() {
+		authorizationHeaders = nil
+
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v2/authentication/access_tokens":
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token": "some-uaa-access-token-longer-than-a-legacy-token"}`))
+			case "/api/v2/products":
+				authorizationHeaders = append(authorizationHeaders, r.Header.Get("Authorization"))
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"products": [{"id": 1, "slug": "elastic-runtime"}, {"id": 2, "slug": "stemcells-ubuntu-jammy"}]}`))
+			case "/api/v2/products/elastic-runtime/releases":
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"releases": [{"id": 3, "version": "2.10.3"}, {"id": 4, "version": "2.11.0"}]}`))
+			case "/api/v2/products/elastic-runtime/releases/4":
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"id": 4, "version": "2.11.0", "release_type": "Major Release"}`))
+			case "/api/v2/products/elastic-runtime/releases/4/product_files/5":
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(w, `{"product_file": {"id": 5, "_links": {"download": {"href": "%s/api/v2/products/elastic-runtime/releases/4/product_files/5/download"}}}}`, serverURL)
+			case "/api/v2/products/elastic-runtime/releases/4/product_files/5/download":
+				http.Redirect(w, r, serverURL+"/files/cf.pivotal", http.StatusFound)
+			case "/files/cf.pivotal":
+				http.ServeContent(w, r, "cf.pivotal", time.Time{}, strings.NewReader(fileContent))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}
+
+		logger := log.New(GinkgoWriter, "", log.LstdFlags)
+		ls = logshim.NewLogShim(logger, logger, true)
+	})
+
+	Describe("Products", func() {
+		var (
+			server *httptest.Server
+			client *pivnetclient.Client
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(handler)
+			serverURL = server.URL
+
+			token := pivnet.NewAccessTokenOrLegacyToken("some-legacy-token", server.URL, false, "unit tests")
+			client = pivnetclient.NewClient(token, pivnet.ClientConfig{Host: server.URL}, nil, ls)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("lists the products", func() {
+			products, err := client.Products()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(products).To(Equal([]pivnet.Product{
+				{ID: 1, Slug: "elastic-runtime"},
+				{ID: 2, Slug: "stemcells-ubuntu-jammy"},
+			}))
+		})
+	})
+
+	Context("when Pivotal Network is signed by a custom certificate authority", func() {
+		var (
+			server *httptest.Server
+			source concourse.Source
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(handler)
+			serverURL = server.URL
+
+			source = concourse.Source{
+				CACerts: []string{
+					string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+				},
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		newClient := func() *pivnetclient.Client {
+			t, err := transport.NewTransport(source)
+			Expect(err).NotTo(HaveOccurred())
+
+			token := pivnetclient.NewAccessTokenOrLegacyToken(refreshToken, server.URL, "unit tests", t)
+			return pivnetclient.NewClient(token, pivnet.ClientConfig{Host: server.URL}, t, ls)
+		}
+
+		It("exchanges the refresh token and makes requests over the transport", func() {
+			products, err := newClient().Products()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(products).To(HaveLen(2))
+			Expect(authorizationHeaders).To(Equal([]string{"Bearer some-uaa-access-token-longer-than-a-legacy-token"}))
+		})
+
+		It("gets releases over the transport", func() {
+			release, err := newClient().GetRelease("elastic-runtime", "2.11.0")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(release.ID).To(Equal(4))
+			Expect(release.ReleaseType).To(Equal(pivnet.ReleaseType("Major Release")))
+		})
+
+		It("downloads product files over the transport", func() {
+			dir, err := ioutil.TempDir("", "pivnetclient")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			file, err := os.Create(filepath.Join(dir, "cf.pivotal"))
+			Expect(err).NotTo(HaveOccurred())
+			defer file.Close()
+
+			location, err := download.NewFileInfo(file)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = newClient().DownloadProductFile(location, "elastic-runtime", 4, 5, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ioutil.ReadFile(file.Name())).To(Equal([]byte(fileContent)))
+		})
+
+		Context("when the release does not exist", func() {
+			It("returns an error naming it", func() {
+				_, err := newClient().GetRelease("elastic-runtime", "2.12.0")
+				Expect(err).To(MatchError("release not found for 'elastic-runtime/2.12.0'"))
+			})
+		})
+
+		Context("when the certificate authority is not provided", func() {
+			BeforeEach(func() {
+				source.CACerts = nil
+			})
+
+			It("returns an error", func() {
+				_, err := newClient().Products()
+				Expect(err).To(MatchError(ContainSubstring("certificate")))
+			})
+		})
+	})
+
+	Describe("AccessTokenOrLegacyToken", func() {
+		It("returns legacy tokens as is", func() {
+			token := pivnetclient.NewAccessTokenOrLegacyToken("some-legacy-token", "http://unused", "unit tests", nil)
+
+			Expect(token.AccessToken()).To(Equal("some-legacy-token"))
+		})
+
+		Context("when the token cannot be exchanged", func() {
+			var server *httptest.Server
+
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusUnauthorized)
+				}))
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			It("returns an error", func() {
+				token := pivnetclient.NewAccessTokenOrLegacyToken(refreshToken, server.URL, "unit tests", nil)
+
+				_, err := token.AccessToken()
+				Expect(err).To(MatchError("failed to fetch API token - received status 401"))
+			})
+		})
+	})
+})
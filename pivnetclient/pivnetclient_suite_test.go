@@ -0,0 +1,13 @@
+package pivnetclient_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestPivnetclient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pivnetclient Suite")
+}
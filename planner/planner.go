@@ -0,0 +1,147 @@
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/pivotal-cf/go-pivnet/v7/logger"
+)
+
+//go:generate counterfeiter --fake-name FakePivnetClient . pivnetClient
+type pivnetClient interface {
+	ReleasesForProductSlug(string) ([]pivnet.Release, error)
+	ReleaseUpgradePaths(string, int) ([]pivnet.ReleaseUpgradePath, error)
+	ReleaseDependencies(string, int) ([]pivnet.ReleaseDependency, error)
+}
+
+// Hop : a single product release which must be installed along the upgrade path
+type Hop struct {
+	ReleaseID          int      `json:"release_id"`
+	ProductVersion     string   `json:"product_version"`
+	DependencyVersions []string `json:"dependency_versions"`
+}
+
+// Plan : the ordered product releases required to upgrade from one product version to another
+type Plan struct {
+	ProductSlug    string `json:"product_slug"`
+	DependencySlug string `json:"dependency_slug"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Hops           []Hop  `json:"hops"`
+}
+
+// Planner : plans upgrades between product releases using the PivNet upgrade paths
+type Planner struct {
+	logger       logger.Logger
+	pivnetClient pivnetClient
+}
+
+// NewPlanner : Creates an instance of the Planner
+func NewPlanner(
+	logger logger.Logger,
+	pivnetClient pivnetClient,
+) *Planner {
+	return &Planner{
+		logger:       logger,
+		pivnetClient: pivnetClient,
+	}
+}
+
+// Plan : finds the shortest chain of product releases to upgrade from the from version to the to version, along with
+// the dependency versions declared by each release in the chain
+func (p *Planner) Plan(productSlug string, dependencySlug string, from string, to string) (Plan, error) {
+	plan := Plan{
+		ProductSlug:    productSlug,
+		DependencySlug: dependencySlug,
+		From:           from,
+		To:             to,
+		Hops:           []Hop{},
+	}
+
+	p.logger.Info(fmt.Sprintf("Getting all product releases for '%s'", productSlug))
+	productReleases, err := p.pivnetClient.ReleasesForProductSlug(productSlug)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	fromRelease, ok := releaseForVersion(productReleases, from)
+	if !ok {
+		return Plan{}, fmt.Errorf("cannot find product release '%s/%s' to upgrade from", productSlug, from)
+	}
+
+	toRelease, ok := releaseForVersion(productReleases, to)
+	if !ok {
+		return Plan{}, fmt.Errorf("cannot find product release '%s/%s' to upgrade to", productSlug, to)
+	}
+
+	if fromRelease.ID == toRelease.ID {
+		return plan, nil
+	}
+
+	// Upgrade paths are declared on the release being upgraded to, so search backwards from the target release
+	// until the release being upgraded from is found, recording which release each one upgrades to.
+	upgradesTo := make(map[int]pivnet.UpgradePathRelease)
+	visited := map[int]bool{toRelease.ID: true}
+	queue := []pivnet.UpgradePathRelease{{ID: toRelease.ID, Version: toRelease.Version}}
+	for len(queue) > 0 && !visited[fromRelease.ID] {
+		current := queue[0]
+		queue = queue[1:]
+
+		p.logger.Info(fmt.Sprintf("Getting upgrade paths for '%s/%s'", productSlug, current.Version))
+		upgradePaths, err := p.pivnetClient.ReleaseUpgradePaths(productSlug, current.ID)
+		if err != nil {
+			return Plan{}, err
+		}
+
+		for _, upgradePath := range upgradePaths {
+			if visited[upgradePath.Release.ID] {
+				continue
+			}
+
+			visited[upgradePath.Release.ID] = true
+			upgradesTo[upgradePath.Release.ID] = current
+			queue = append(queue, upgradePath.Release)
+		}
+	}
+
+	if !visited[fromRelease.ID] {
+		return Plan{}, fmt.Errorf("cannot find an upgrade path for '%s' from '%s' to '%s'", productSlug, from, to)
+	}
+
+	for id := fromRelease.ID; id != toRelease.ID; {
+		next := upgradesTo[id]
+
+		p.logger.Info(fmt.Sprintf("Getting release dependencies for '%s/%s'", productSlug, next.Version))
+		releaseDependencies, err := p.pivnetClient.ReleaseDependencies(productSlug, next.ID)
+		if err != nil {
+			return Plan{}, err
+		}
+
+		dependencyVersions := []string{}
+		for _, releaseDependency := range releaseDependencies {
+			if strings.Contains(releaseDependency.Release.Product.Slug, dependencySlug) {
+				dependencyVersions = append(dependencyVersions, releaseDependency.Release.Version)
+			}
+		}
+
+		plan.Hops = append(plan.Hops, Hop{
+			ReleaseID:          next.ID,
+			ProductVersion:     next.Version,
+			DependencyVersions: dependencyVersions,
+		})
+
+		id = next.ID
+	}
+
+	return plan, nil
+}
+
+func releaseForVersion(releases []pivnet.Release, version string) (pivnet.Release, bool) {
+	for _, release := range releases {
+		if release.Version == version {
+			return release, true
+		}
+	}
+	return pivnet.Release{}, false
+}
@@ -0,0 +1,179 @@
+package planner_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/planner"
+	"github.com/shanman190/pivnet-product-stemcell-resource/planner/plannerfakes"
+
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/pivotal-cf/go-pivnet/v7/logger"
+	"github.com/pivotal-cf/go-pivnet/v7/logshim"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Planner", func() {
+	var (
+		fakeLogger       logger.Logger
+		fakePivnetClient *plannerfakes.FakePivnetClient
+
+		p *planner.Planner
+
+		upgradePaths        map[int][]pivnet.ReleaseUpgradePath
+		upgradePathsErr     error
+		releaseDependencies map[int][]pivnet.ReleaseDependency
+
+		upgradePath = func(id int, version string) pivnet.ReleaseUpgradePath {
+			return pivnet.ReleaseUpgradePath{
+				Release: pivnet.UpgradePathRelease{ID: id, Version: version},
+			}
+		}
+
+		stemcellDependency = func(version string) pivnet.ReleaseDependency {
+			return pivnet.ReleaseDependency{
+				Release: pivnet.DependentRelease{
+					Version: version,
+					Product: pivnet.Product{Slug: "some-stemcell"},
+				},
+			}
+		}
+	)
+
+	BeforeEach(func() {
+		logger := log.New(GinkgoWriter, "", log.LstdFlags)
+		fakeLogger = logshim.NewLogShim(logger, logger, true)
+
+		fakePivnetClient = &plannerfakes.FakePivnetClient{}
+
+		fakePivnetClient.ReleasesForProductSlugReturns([]pivnet.Release{
+			{ID: 4, Version: "2.1.0"},
+			{ID: 3, Version: "2.0.0"},
+			{ID: 2, Version: "1.1.0"},
+			{ID: 1, Version: "1.0.0"},
+		}, nil)
+
+		upgradePathsErr = nil
+		upgradePaths = map[int][]pivnet.ReleaseUpgradePath{
+			4: {upgradePath(3, "2.0.0")},
+			3: {upgradePath(2, "1.1.0")},
+			2: {upgradePath(1, "1.0.0")},
+		}
+
+		releaseDependencies = map[int][]pivnet.ReleaseDependency{
+			2: {stemcellDependency("100.1")},
+			3: {stemcellDependency("200.1"), stemcellDependency("200.2")},
+			4: {stemcellDependency("200.2")},
+		}
+	})
+
+	JustBeforeEach(func() {
+		fakePivnetClient.ReleaseUpgradePathsStub = func(productSlug string, releaseID int) ([]pivnet.ReleaseUpgradePath, error) {
+			return upgradePaths[releaseID], upgradePathsErr
+		}
+		fakePivnetClient.ReleaseDependenciesStub = func(productSlug string, releaseID int) ([]pivnet.ReleaseDependency, error) {
+			return releaseDependencies[releaseID], nil
+		}
+
+		p = planner.NewPlanner(fakeLogger, fakePivnetClient)
+	})
+
+	It("returns each release to upgrade through along with its stemcells", func() {
+		plan, err := p.Plan("some-product", "some-stemcell", "1.0.0", "2.1.0")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(plan.ProductSlug).To(Equal("some-product"))
+		Expect(plan.DependencySlug).To(Equal("some-stemcell"))
+		Expect(plan.From).To(Equal("1.0.0"))
+		Expect(plan.To).To(Equal("2.1.0"))
+		Expect(plan.Hops).To(Equal([]planner.Hop{
+			{ReleaseID: 2, ProductVersion: "1.1.0", DependencyVersions: []string{"100.1"}},
+			{ReleaseID: 3, ProductVersion: "2.0.0", DependencyVersions: []string{"200.1", "200.2"}},
+			{ReleaseID: 4, ProductVersion: "2.1.0", DependencyVersions: []string{"200.2"}},
+		}))
+	})
+
+	Context("when a shorter upgrade path exists", func() {
+		BeforeEach(func() {
+			upgradePaths[4] = []pivnet.ReleaseUpgradePath{upgradePath(3, "2.0.0"), upgradePath(1, "1.0.0")}
+		})
+
+		It("returns the shortest upgrade path", func() {
+			plan, err := p.Plan("some-product", "some-stemcell", "1.0.0", "2.1.0")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(plan.Hops).To(Equal([]planner.Hop{
+				{ReleaseID: 4, ProductVersion: "2.1.0", DependencyVersions: []string{"200.2"}},
+			}))
+		})
+	})
+
+	Context("when upgrading to the same version", func() {
+		It("returns no hops", func() {
+			plan, err := p.Plan("some-product", "some-stemcell", "2.0.0", "2.0.0")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(plan.Hops).To(BeEmpty())
+			Expect(fakePivnetClient.ReleaseUpgradePathsCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when there is no upgrade path", func() {
+		BeforeEach(func() {
+			upgradePaths[2] = nil
+		})
+
+		It("returns an error", func() {
+			_, err := p.Plan("some-product", "some-stemcell", "1.0.0", "2.1.0")
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("cannot find an upgrade path for 'some-product' from '1.0.0' to '2.1.0'"))
+		})
+	})
+
+	Context("when the version to upgrade from does not exist", func() {
+		It("returns an error", func() {
+			_, err := p.Plan("some-product", "some-stemcell", "0.9.0", "2.1.0")
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("cannot find product release 'some-product/0.9.0' to upgrade from"))
+		})
+	})
+
+	Context("when the version to upgrade to does not exist", func() {
+		It("returns an error", func() {
+			_, err := p.Plan("some-product", "some-stemcell", "1.0.0", "3.0.0")
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("cannot find product release 'some-product/3.0.0' to upgrade to"))
+		})
+	})
+
+	Context("when getting upgrade paths returns an error", func() {
+		BeforeEach(func() {
+			upgradePathsErr = fmt.Errorf("some upgrade paths error")
+		})
+
+		It("returns the error", func() {
+			_, err := p.Plan("some-product", "some-stemcell", "1.0.0", "2.1.0")
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("some upgrade paths error"))
+		})
+	})
+
+	Context("when getting releases returns an error", func() {
+		BeforeEach(func() {
+			fakePivnetClient.ReleasesForProductSlugReturns(nil, fmt.Errorf("some releases error"))
+		})
+
+		It("returns the error", func() {
+			_, err := p.Plan("some-product", "some-stemcell", "1.0.0", "2.1.0")
+			Expect(err).To(HaveOccurred())
+
+			Expect(err.Error()).To(ContainSubstring("some releases error"))
+		})
+	})
+})
@@ -0,0 +1,178 @@
+package sanitizer
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pivotal-cf/go-pivnet/v7"
+)
+
+const (
+	accessTokenReplacement   = "***REDACTED-PIVNET_ACCESS_TOKEN***"
+	authorizationReplacement = "***REDACTED-AUTHORIZATION***"
+	signatureReplacement     = "***REDACTED-SIGNATURE***"
+)
+
+var (
+	// signatureParams are the query parameters which authorize a signed download URL, e.g. from S3 or CloudFront
+	signatureParams = []string{
+		"X-Amz-Signature",
+		"X-Amz-Credential",
+		"X-Amz-Security-Token",
+		"Signature",
+		"Policy",
+		"Key-Pair-Id",
+	}
+
+	authorizationRegex = regexp.MustCompile(`(?i)(Authorization:\s*(?:Bearer|Token)\s+)[^\s\\"]+`)
+	signatureRegex     = regexp.MustCompile(`(?i)([?&](?:` + strings.Join(signatureParams, "|") + `)=)[^&\s\\"]+`)
+)
+
+// Sanitizer : redacts secrets from output, learning secrets such as access tokens as they are obtained at runtime
+type Sanitizer struct {
+	mu      sync.RWMutex
+	secrets map[string]string
+}
+
+// NewSanitizer : Creates an instance of the Sanitizer, redacting each secret with its replacement
+func NewSanitizer(secrets map[string]string) *Sanitizer {
+	s := &Sanitizer{
+		secrets: make(map[string]string),
+	}
+	s.LearnAll(secrets)
+	return s
+}
+
+// Learn : redacts the secret with the replacement from now on, unless it is already redacted
+func (s *Sanitizer) Learn(secret string, replacement string) {
+	if secret == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.secrets[secret]; !ok {
+		s.secrets[secret] = replacement
+	}
+}
+
+// LearnAll : redacts each secret with its replacement from now on
+func (s *Sanitizer) LearnAll(secrets map[string]string) {
+	for secret, replacement := range secrets {
+		s.Learn(secret, replacement)
+	}
+}
+
+// LearnURL : redacts the signature query parameters of the URL from now on, so that they are also redacted when they
+// appear outside of the URL
+func (s *Sanitizer) LearnURL(rawURL string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+
+	for key, values := range u.Query() {
+		if !isSignatureParam(key) {
+			continue
+		}
+		for _, value := range values {
+			s.Learn(value, signatureReplacement)
+			s.Learn(url.QueryEscape(value), signatureReplacement)
+		}
+	}
+}
+
+// Sanitize : redacts every learned secret, authorization header and URL signature from the text
+func (s *Sanitizer) Sanitize(text string) string {
+	s.mu.RLock()
+	secrets := make([]string, 0, len(s.secrets))
+	for secret := range s.secrets {
+		secrets = append(secrets, secret)
+	}
+	// longest first, so that a secret containing another is redacted whole
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, s.secrets[secret])
+	}
+	s.mu.RUnlock()
+
+	text = authorizationRegex.ReplaceAllString(text, "${1}"+authorizationReplacement)
+	return signatureRegex.ReplaceAllString(text, "${1}"+signatureReplacement)
+}
+
+// Writer : a writer which sanitizes everything written before writing it to sink
+func (s *Sanitizer) Writer(sink io.Writer) io.Writer {
+	return writer{sanitizer: s, sink: sink}
+}
+
+// AccessTokenService : wraps the token service, learning every access token it returns
+func (s *Sanitizer) AccessTokenService(tokens pivnet.AccessTokenService) pivnet.AccessTokenService {
+	return accessTokenService{sanitizer: s, tokens: tokens}
+}
+
+// Transport : wraps the transport, learning the credentials of every request and the signatures of redirects
+func (s *Sanitizer) Transport(transport http.RoundTripper) http.RoundTripper {
+	return roundTripper{sanitizer: s, transport: transport}
+}
+
+func isSignatureParam(key string) bool {
+	for _, param := range signatureParams {
+		if strings.EqualFold(key, param) {
+			return true
+		}
+	}
+	return false
+}
+
+type writer struct {
+	sanitizer *Sanitizer
+	sink      io.Writer
+}
+
+func (w writer) Write(p []byte) (int, error) {
+	_, err := io.WriteString(w.sink, w.sanitizer.Sanitize(string(p)))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+type accessTokenService struct {
+	sanitizer *Sanitizer
+	tokens    pivnet.AccessTokenService
+}
+
+func (a accessTokenService) AccessToken() (string, error) {
+	token, err := a.tokens.AccessToken()
+	if err != nil {
+		return "", err
+	}
+
+	a.sanitizer.Learn(token, accessTokenReplacement)
+	return token, nil
+}
+
+type roundTripper struct {
+	sanitizer *Sanitizer
+	transport http.RoundTripper
+}
+
+func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.sanitizer.LearnURL(req.URL.String())
+	if fields := strings.Fields(req.Header.Get("Authorization")); len(fields) == 2 {
+		r.sanitizer.Learn(fields[1], authorizationReplacement)
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if resp != nil {
+		r.sanitizer.LearnURL(resp.Header.Get("Location"))
+	}
+	return resp, err
+}
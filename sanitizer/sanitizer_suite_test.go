@@ -0,0 +1,13 @@
+package sanitizer_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSanitizer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sanitizer Suite")
+}
@@ -0,0 +1,138 @@
+package sanitizer_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet/v7/go-pivnetfakes"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/sanitizer"
+)
+
+var _ = Describe("Sanitizer", func() {
+	var s *sanitizer.Sanitizer
+
+	BeforeEach(func() {
+		s = sanitizer.NewSanitizer(map[string]string{
+			"some-api-token": "***REDACTED-PIVNET_API_TOKEN***",
+		})
+	})
+
+	Describe("Sanitize", func() {
+		It("redacts the secrets it was created with", func() {
+			Expect(s.Sanitize("token some-api-token used")).To(Equal("token ***REDACTED-PIVNET_API_TOKEN*** used"))
+		})
+
+		It("redacts secrets learned later", func() {
+			s.Learn("some-access-token", "***REDACTED***")
+
+			Expect(s.Sanitize("some-access-token and some-api-token")).To(Equal("***REDACTED*** and ***REDACTED-PIVNET_API_TOKEN***"))
+		})
+
+		It("keeps the first replacement of a secret", func() {
+			s.Learn("some-api-token", "***REDACTED***")
+
+			Expect(s.Sanitize("some-api-token")).To(Equal("***REDACTED-PIVNET_API_TOKEN***"))
+		})
+
+		It("redacts secrets containing other secrets whole", func() {
+			s.Learn("some-api-token-and-more", "***LONGER***")
+
+			Expect(s.Sanitize("some-api-token-and-more")).To(Equal("***LONGER***"))
+		})
+
+		It("redacts authorization headers", func() {
+			dump := "GET /api/v2/products HTTP/1.1\r\nAuthorization: Bearer abc.def.ghi\r\nContent-Type: application/json\r\n"
+
+			Expect(s.Sanitize(dump)).To(Equal("GET /api/v2/products HTTP/1.1\r\nAuthorization: Bearer ***REDACTED-AUTHORIZATION***\r\nContent-Type: application/json\r\n"))
+		})
+
+		It("redacts the signatures of signed URLs", func() {
+			signedURL := "https://bucket.s3.amazonaws.com/product.pivotal?X-Amz-Credential=AKIA%2F20240101&X-Amz-Date=20240101T000000Z&X-Amz-Signature=abc123"
+
+			Expect(s.Sanitize(signedURL)).To(Equal("https://bucket.s3.amazonaws.com/product.pivotal?X-Amz-Credential=***REDACTED-SIGNATURE***&X-Amz-Date=20240101T000000Z&X-Amz-Signature=***REDACTED-SIGNATURE***"))
+		})
+	})
+
+	Describe("LearnURL", func() {
+		It("redacts the signatures wherever they appear", func() {
+			s.LearnURL("https://cdn.example.com/product.pivotal?Expires=1700000000&Signature=some-signature&Key-Pair-Id=some-key-pair")
+
+			Expect(s.Sanitize("signature some-signature for some-key-pair expiring 1700000000")).To(Equal("signature ***REDACTED-SIGNATURE*** for ***REDACTED-SIGNATURE*** expiring 1700000000"))
+		})
+	})
+
+	Describe("Writer", func() {
+		It("writes sanitized output, reporting the length of the original", func() {
+			sink := &bytes.Buffer{}
+
+			n, err := s.Writer(sink).Write([]byte("using some-api-token"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(len("using some-api-token")))
+
+			Expect(sink.String()).To(Equal("using ***REDACTED-PIVNET_API_TOKEN***"))
+		})
+	})
+
+	Describe("AccessTokenService", func() {
+		var fakeAccessTokenService *gopivnetfakes.FakeAccessTokenService
+
+		BeforeEach(func() {
+			fakeAccessTokenService = &gopivnetfakes.FakeAccessTokenService{}
+			fakeAccessTokenService.AccessTokenReturns("some-exchanged-access-token", nil)
+		})
+
+		It("learns the access tokens it returns", func() {
+			token, err := s.AccessTokenService(fakeAccessTokenService).AccessToken()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("some-exchanged-access-token"))
+
+			Expect(s.Sanitize(fmt.Sprintf("failed with token %s", token))).To(Equal("failed with token ***REDACTED-PIVNET_ACCESS_TOKEN***"))
+		})
+
+		Context("when the token cannot be obtained", func() {
+			BeforeEach(func() {
+				fakeAccessTokenService.AccessTokenReturns("", errors.New("some error"))
+			})
+
+			It("returns the error", func() {
+				_, err := s.AccessTokenService(fakeAccessTokenService).AccessToken()
+				Expect(err).To(MatchError("some error"))
+			})
+		})
+	})
+
+	Describe("Transport", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Location", "https://bucket.s3.amazonaws.com/product.pivotal?X-Amz-Signature=some-redirect-signature")
+				w.WriteHeader(http.StatusFound)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("learns the authorization of requests and the signatures of redirects", func() {
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/download?Signature=some-request-signature", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer some-bearer-token")
+
+			resp, err := s.Transport(http.DefaultTransport).RoundTrip(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+
+			Expect(s.Sanitize("some-bearer-token some-request-signature some-redirect-signature")).To(Equal(
+				"***REDACTED-AUTHORIZATION*** ***REDACTED-SIGNATURE*** ***REDACTED-SIGNATURE***",
+			))
+		})
+	})
+})
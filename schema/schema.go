@@ -0,0 +1,269 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/pivotal-cf/go-pivnet/v7"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+)
+
+const draft = "http://json-schema.org/draft-07/schema#"
+
+// Property : the documentation of a property which cannot be derived from its struct field
+type Property struct {
+	Description string
+	Default     interface{}
+	Required    bool
+}
+
+// Properties : documentation of every property, keyed by its path from the request, e.g. 'source.sort_by'
+var Properties = map[string]Property{
+	"source":                            {Description: "Configuration of the resource.", Required: true},
+	"source.api_token":                  {Description: "Legacy API token or UAA refresh token from your Pivotal Network profile."},
+	"source.api_token_file":             {Description: "Path of a file on the worker containing the api_token, as an alternative to api_token."},
+	"source.api_token_env":              {Description: "Name of an environment variable of the worker containing the api_token, as an alternative to api_token."},
+	"source.require_refresh_token":      {Description: "Reject legacy API tokens, only accepting UAA refresh tokens.", Default: false},
+	"source.product_slug":               {Description: "Name of the product on Pivotal Network."},
+	"source.product_slugs":              {Description: "Names of the products to consider when mode is reverse. Takes precedence over product_slug."},
+	"source.product_version":            {Description: "Regular expression, or list of regular expressions, which product versions must match."},
+	"source.product_version_constraint": {Description: "Semver range which product versions must satisfy, as an alternative to product_version."},
+	"source.stemcell_slug":              {Description: "Name of the stemcell on Pivotal Network. Detected from the product release dependencies when neither stemcell_slug nor dependency_slug are provided."},
+	"source.dependency_slug":            {Description: "Name of any other product on Pivotal Network the product declares as a dependency."},
+	"source.opsman_version":             {Description: "Ops Manager version, or semver range, which product releases must support."},
+	"source.endpoint":                   {Description: "Endpoint of Pivotal Network.", Default: pivnet.DefaultHost},
+	"source.release_type":               {Description: "Release type, or list of release types, which product releases must have."},
+	"source.availability":               {Description: "Availabilities which product and dependency releases must have."},
+	"source.exclude_past_eogs":          {Description: "Exclude releases past their end of general support date.", Default: false},
+	"source.min_release_age":            {Description: "Minimum age of releases, as a Go duration such as 72h or a number of days such as 3d."},
+	"source.min_release_age.product":    {Description: "Minimum age of product releases."},
+	"source.min_release_age.stemcell":   {Description: "Minimum age of stemcell or dependency releases."},
+	"source.exclude_product_versions":   {Description: "Exact versions, regular expressions or semver ranges of product versions to ignore."},
+	"source.exclude_stemcell_versions":  {Description: "Exact versions, regular expressions or semver ranges of stemcell or dependency versions to ignore."},
+	"source.sort_by":                    {Description: "Order to use for sorting releases.", Default: string(concourse.SortByNone)},
+	"source.mode":                       {Description: "Direction to discover dependencies in.", Default: string(concourse.ModeForward)},
+	"source.track":                      {Description: "Follow the newest product release of each minor or major line rather than a single history."},
+	"source.skip_ssl_verification":      {Description: "Skip verification of the Pivotal Network certificate.", Default: false},
+	"source.ca_certs":                   {Description: "PEM encoded certificate authorities to trust in addition to the system ones, e.g. of a TLS intercepting proxy."},
+	"source.client_cert":                {Description: "PEM encoded client certificate to present, requires client_key."},
+	"source.client_key":                 {Description: "PEM encoded private key of client_cert."},
+	"source.http_proxy":                 {Description: "Proxy for HTTP requests, overriding the HTTP_PROXY environment variable."},
+	"source.https_proxy":                {Description: "Proxy for HTTPS requests, overriding the HTTPS_PROXY environment variable."},
+	"source.no_proxy":                   {Description: "Comma separated hosts, domains and CIDRs to connect to directly, overriding the NO_PROXY environment variable."},
+	"source.cache_access_token":         {Description: "Cache the access token exchanged for a UAA refresh token between checks on the same worker.", Default: false},
+	"source.copy_metadata":              {Description: "Copy the release metadata to the root of the get step's output.", Default: false},
+	"source.verbose":                    {Description: "Enable verbose logging.", Default: false},
+	"source.timeout":                    {Description: "Maximum duration of a check or get, as a Go duration such as 10m. There is no timeout when omitted."},
+	"source.log_retention":              {Description: "Number of check log files to keep on the worker.", Default: concourse.DefaultLogRetention},
+	"source.log_level":                  {Description: "Level of the messages check writes to stderr, and to its log file when more verbose than info. Takes precedence over verbose."},
+	"version":                           {Description: "Version of the resource."},
+	"version.product_version":           {Description: "Fingerprinted version of the product release."},
+	"version.stemcell_version":          {Description: "Fingerprinted version of the stemcell release."},
+	"version.dependency_version":        {Description: "Fingerprinted version of the dependency release."},
+	"version.stemcell_slug":             {Description: "Stemcell slug detected by check."},
+	"params":                            {Description: "Parameters of the step."},
+	"params.globs":                      {Description: "Globs of the product and stemcell files to download. All files are downloaded when omitted."},
+	"params.unpack":                     {Description: "Unpack downloaded archives.", Default: false},
+	"params.upgrade_from":               {Description: "Product version to plan an upgrade path from to the fetched product version."},
+}
+
+// exactlyOneOf : properties of an object of which exactly one must be provided, keyed by the path of the object
+var exactlyOneOf = map[string][]string{
+	"source": {"api_token", "api_token_file", "api_token_env"},
+}
+
+var enums = map[reflect.Type][]interface{}{
+	reflect.TypeOf(concourse.SortBy("")): sortByEnum(),
+	reflect.TypeOf(concourse.Mode("")): {
+		string(concourse.ModeForward),
+		string(concourse.ModeReverse),
+	},
+	reflect.TypeOf(concourse.LogLevel("")): logLevelEnum(),
+	reflect.TypeOf(concourse.Track("")): {
+		string(concourse.TrackPerMinor),
+		string(concourse.TrackPerMajor),
+	},
+}
+
+func sortByEnum() []interface{} {
+	enum := make([]interface{}, len(concourse.SortBys))
+	for i, sortBy := range concourse.SortBys {
+		enum[i] = string(sortBy)
+	}
+	return enum
+}
+
+func logLevelEnum() []interface{} {
+	enum := make([]interface{}, len(concourse.LogLevels))
+	for i, logLevel := range concourse.LogLevels {
+		enum[i] = string(logLevel)
+	}
+	return enum
+}
+
+var stringListType = reflect.TypeOf(concourse.StringList{})
+
+// CheckRequest : the JSON Schema of the request to check
+func CheckRequest() (map[string]interface{}, error) {
+	return document("check", concourse.CheckRequest{})
+}
+
+// InRequest : the JSON Schema of the request to in, the get step
+func InRequest() (map[string]interface{}, error) {
+	return document("in", concourse.InRequest{})
+}
+
+// OutRequest : the JSON Schema of the request to out, the put step
+func OutRequest() (map[string]interface{}, error) {
+	return document("out", concourse.OutRequest{})
+}
+
+func document(step string, request interface{}) (map[string]interface{}, error) {
+	s, err := For("", reflect.TypeOf(request))
+	if err != nil {
+		return nil, err
+	}
+
+	s["$schema"] = draft
+	s["title"] = fmt.Sprintf("pivnet-product-stemcell-resource %s request", step)
+	return s, nil
+}
+
+// For : the JSON Schema of the type found at the path of a request, erroring if any of its properties are undocumented
+func For(path string, t reflect.Type) (map[string]interface{}, error) {
+	s := make(map[string]interface{})
+
+	if path != "" {
+		p, ok := Properties[path]
+		if !ok {
+			return nil, fmt.Errorf("property '%s' is not documented", path)
+		}
+		s["description"] = p.Description
+		if p.Default != nil {
+			s["default"] = p.Default
+		}
+	}
+
+	if enum, ok := enums[t]; ok {
+		s["type"] = "string"
+		s["enum"] = enum
+		return s, nil
+	}
+
+	if t == stringListType {
+		s["oneOf"] = []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		}
+		return s, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		s["type"] = "string"
+	case reflect.Bool:
+		s["type"] = "boolean"
+	case reflect.Int, reflect.Int64:
+		s["type"] = "integer"
+	case reflect.Slice:
+		items, err := For("", t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		s["type"] = "array"
+		s["items"] = items
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		var required []string
+		for _, name := range FieldNames(t) {
+			field, _ := fieldByJSONName(t, name)
+			propertyPath := join(path, name)
+
+			property, err := For(propertyPath, field.Type)
+			if err != nil {
+				return nil, err
+			}
+			properties[name] = property
+
+			if Properties[propertyPath].Required {
+				required = append(required, name)
+			}
+		}
+
+		s["type"] = "object"
+		s["properties"] = properties
+		s["additionalProperties"] = false
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		if names, ok := exactlyOneOf[path]; ok {
+			var oneOf []interface{}
+			for _, name := range names {
+				oneOf = append(oneOf, map[string]interface{}{"required": []string{name}})
+			}
+			s["oneOf"] = oneOf
+		}
+	default:
+		return nil, fmt.Errorf("property '%s' has unsupported type: %s", path, t)
+	}
+
+	return s, nil
+}
+
+// FieldNames : the JSON names of the exported fields of the struct, in declaration order
+func FieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if name := jsonName(field); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func fieldByJSONName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if jsonName(t.Field(i)) == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func jsonName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// Print : writes the generated JSON Schema to w as indented JSON
+func Print(w io.Writer, generate func() (map[string]interface{}, error)) error {
+	s, err := generate()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
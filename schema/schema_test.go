@@ -0,0 +1,146 @@
+package schema_test
+
+import (
+	"reflect"
+	"sort"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/schema"
+)
+
+// paths returns the path of every property of the struct, following nested structs
+func paths(prefix string, t reflect.Type) []string {
+	var all []string
+	for _, name := range schema.FieldNames(t) {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		all = append(all, path)
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Type.Kind() == reflect.Struct && (field.Tag.Get("json") == name || field.Name == name) {
+				all = append(all, paths(path, field.Type)...)
+			}
+		}
+	}
+	return all
+}
+
+var _ = Describe("Schema", func() {
+	It("documents exactly the properties of the requests", func() {
+		seen := make(map[string]bool)
+		for _, t := range []reflect.Type{
+			reflect.TypeOf(concourse.CheckRequest{}),
+			reflect.TypeOf(concourse.InRequest{}),
+			reflect.TypeOf(concourse.OutRequest{}),
+		} {
+			for _, path := range paths("", t) {
+				seen[path] = true
+			}
+		}
+
+		var fieldPaths []string
+		for path := range seen {
+			fieldPaths = append(fieldPaths, path)
+		}
+		sort.Strings(fieldPaths)
+
+		var documentedPaths []string
+		for path := range schema.Properties {
+			documentedPaths = append(documentedPaths, path)
+		}
+		sort.Strings(documentedPaths)
+
+		Expect(documentedPaths).To(Equal(fieldPaths))
+	})
+
+	It("generates the schema of each request", func() {
+		for _, generate := range []func() (map[string]interface{}, error){
+			schema.CheckRequest,
+			schema.InRequest,
+			schema.OutRequest,
+		} {
+			s, err := generate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s["$schema"]).To(Equal("http://json-schema.org/draft-07/schema#"))
+			Expect(s["type"]).To(Equal("object"))
+		}
+	})
+
+	Describe("the in request", func() {
+		var source map[string]interface{}
+		var params map[string]interface{}
+
+		BeforeEach(func() {
+			s, err := schema.InRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			properties := s["properties"].(map[string]interface{})
+			source = properties["source"].(map[string]interface{})
+			params = properties["params"].(map[string]interface{})
+			Expect(s["required"]).To(Equal([]string{"source"}))
+		})
+
+		It("rejects unknown keys", func() {
+			Expect(source["additionalProperties"]).To(Equal(false))
+			Expect(source).NotTo(HaveKey("required"))
+		})
+
+		It("requires exactly one of the api token sources", func() {
+			Expect(source["oneOf"]).To(Equal([]interface{}{
+				map[string]interface{}{"required": []string{"api_token"}},
+				map[string]interface{}{"required": []string{"api_token_file"}},
+				map[string]interface{}{"required": []string{"api_token_env"}},
+			}))
+		})
+
+		It("describes properties with their defaults", func() {
+			endpoint := source["properties"].(map[string]interface{})["endpoint"].(map[string]interface{})
+			Expect(endpoint["type"]).To(Equal("string"))
+			Expect(endpoint["description"]).NotTo(BeEmpty())
+			Expect(endpoint["default"]).To(Equal("https://network.tanzu.vmware.com"))
+		})
+
+		It("enumerates the sort by values", func() {
+			sortBy := source["properties"].(map[string]interface{})["sort_by"].(map[string]interface{})
+			Expect(sortBy["enum"]).To(Equal([]interface{}{"none", "semver", "last_updated", "release_date", "stemcell_first"}))
+			Expect(sortBy["default"]).To(Equal("none"))
+		})
+
+		It("enumerates the log levels", func() {
+			logLevel := source["properties"].(map[string]interface{})["log_level"].(map[string]interface{})
+			Expect(logLevel["enum"]).To(Equal([]interface{}{"error", "info", "debug", "trace"}))
+		})
+
+		It("accepts a string or a list of strings for string lists", func() {
+			releaseType := source["properties"].(map[string]interface{})["release_type"].(map[string]interface{})
+			Expect(releaseType["oneOf"]).To(HaveLen(2))
+		})
+
+		It("describes nested objects", func() {
+			minReleaseAge := source["properties"].(map[string]interface{})["min_release_age"].(map[string]interface{})
+			Expect(minReleaseAge["type"]).To(Equal("object"))
+			Expect(minReleaseAge["properties"]).To(HaveKey("stemcell"))
+		})
+
+		It("describes the params", func() {
+			Expect(params["properties"]).To(HaveKey("upgrade_from"))
+		})
+	})
+
+	Describe("For", func() {
+		It("returns an error for undocumented properties", func() {
+			type undocumented struct {
+				Field string `json:"field"`
+			}
+
+			_, err := schema.For("", reflect.TypeOf(undocumented{}))
+			Expect(err).To(MatchError("property 'field' is not documented"))
+		})
+	})
+})
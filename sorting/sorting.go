@@ -0,0 +1,107 @@
+package sorting
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/pivotal-cf/go-pivnet/v7/logger"
+	pivnetconcourse "github.com/pivotal-cf/pivnet-resource/v3/concourse"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+)
+
+const (
+	releaseDateLayout = "2006-01-02"
+)
+
+//go:generate counterfeiter --fake-name FakeSorter . sorter
+type sorter interface {
+	SortBySemver([]pivnet.Release) ([]pivnet.Release, error)
+	SortByLastUpdated([]pivnet.Release) ([]pivnet.Release, error)
+}
+
+// Sorter : sorts releases in the order configured by sort_by
+type Sorter struct {
+	logger logger.Logger
+	sorter sorter
+}
+
+// NewSorter : Creates an instance of the Sorter
+func NewSorter(logger logger.Logger, sorter sorter) *Sorter {
+	return &Sorter{
+		logger: logger,
+		sorter: sorter,
+	}
+}
+
+// Sort : sorts the releases of the subject, newest first, leaving them in the order they came back from Pivotal
+// Network when sort_by is none or not provided
+func (s Sorter) Sort(subject string, releases []pivnet.Release, sortBy concourse.SortBy) ([]pivnet.Release, error) {
+	switch sortBy {
+	case concourse.SortBySemver, concourse.SortByStemcellFirst:
+		s.logger.Info(fmt.Sprintf("Sorting all '%s' releases by semver", subject))
+		return s.sorter.SortBySemver(releases)
+	case concourse.SortByLastUpdated:
+		s.logger.Info(fmt.Sprintf("Sorting all '%s' releases by last updated", subject))
+		return s.sorter.SortByLastUpdated(releases)
+	case concourse.SortByReleaseDate:
+		s.logger.Info(fmt.Sprintf("Sorting all '%s' releases by release date", subject))
+		return byReleaseDate(releases), nil
+	default:
+		return releases, nil
+	}
+}
+
+// PivnetSortBy : the pivnet-resource sort_by to use for the get step. Sort orders which pivnet-resource does not
+// support fall back to the closest order it does.
+func PivnetSortBy(sortBy concourse.SortBy) pivnetconcourse.SortBy {
+	switch sortBy {
+	case concourse.SortBySemver, concourse.SortByStemcellFirst:
+		return pivnetconcourse.SortBySemver
+	case concourse.SortByLastUpdated:
+		return pivnetconcourse.SortByLastUpdated
+	case concourse.SortByNone, concourse.SortByReleaseDate:
+		return pivnetconcourse.SortByNone
+	default:
+		return ""
+	}
+}
+
+// PublishedAt : the release date of the release, falling back to when it was last updated if the release date is
+// missing
+func PublishedAt(release pivnet.Release) (time.Time, error) {
+	if release.ReleaseDate != "" {
+		return time.Parse(releaseDateLayout, release.ReleaseDate)
+	}
+
+	if release.UpdatedAt != "" {
+		return time.Parse(time.RFC3339, release.UpdatedAt)
+	}
+
+	return time.Time{}, fmt.Errorf("neither release date nor updated at are set")
+}
+
+// byReleaseDate sorts the releases by when they were published, newest first, with releases whose publication cannot
+// be determined last
+func byReleaseDate(releases []pivnet.Release) []pivnet.Release {
+	publishedAt := make([]time.Time, len(releases))
+	for i, release := range releases {
+		publishedAt[i], _ = PublishedAt(release)
+	}
+
+	indexes := make([]int, len(releases))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return publishedAt[indexes[i]].After(publishedAt[indexes[j]])
+	})
+
+	sorted := make([]pivnet.Release, len(releases))
+	for i, index := range indexes {
+		sorted[i] = releases[index]
+	}
+	return sorted
+}
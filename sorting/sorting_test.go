@@ -0,0 +1,125 @@
+package sorting_test
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/pivotal-cf/go-pivnet/v7/logshim"
+	pivnetconcourse "github.com/pivotal-cf/pivnet-resource/v3/concourse"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/sorting"
+	"github.com/shanman190/pivnet-product-stemcell-resource/sorting/sortingfakes"
+)
+
+var _ = Describe("Sorter", func() {
+	var (
+		fakeSorter *sortingfakes.FakeSorter
+		releases   []pivnet.Release
+		sorted     []pivnet.Release
+
+		s *sorting.Sorter
+	)
+
+	BeforeEach(func() {
+		logger := log.New(GinkgoWriter, "", log.LstdFlags)
+		ls := logshim.NewLogShim(logger, logger, true)
+
+		fakeSorter = &sortingfakes.FakeSorter{}
+
+		releases = []pivnet.Release{
+			{ID: 1, Version: "1.0.0", ReleaseDate: "2023-01-01"},
+			{ID: 2, Version: "1.1.0", UpdatedAt: "2023-03-01T10:00:00Z"},
+			{ID: 3, Version: "1.0.1"},
+			{ID: 4, Version: "0.9.0", ReleaseDate: "2023-02-01"},
+		}
+		sorted = []pivnet.Release{releases[1], releases[0]}
+
+		fakeSorter.SortBySemverReturns(sorted, nil)
+		fakeSorter.SortByLastUpdatedReturns(sorted, nil)
+
+		s = sorting.NewSorter(ls, fakeSorter)
+	})
+
+	Describe("Sort", func() {
+		It("leaves the releases as they are when sort by is none or not provided", func() {
+			for _, sortBy := range []concourse.SortBy{"", concourse.SortByNone} {
+				result, err := s.Sort("some-product", releases, sortBy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(releases))
+			}
+
+			Expect(fakeSorter.SortBySemverCallCount()).To(Equal(0))
+			Expect(fakeSorter.SortByLastUpdatedCallCount()).To(Equal(0))
+		})
+
+		It("sorts by semver for semver and stemcell first", func() {
+			for _, sortBy := range []concourse.SortBy{concourse.SortBySemver, concourse.SortByStemcellFirst} {
+				result, err := s.Sort("some-product", releases, sortBy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(sorted))
+			}
+
+			Expect(fakeSorter.SortBySemverCallCount()).To(Equal(2))
+		})
+
+		It("sorts by last updated", func() {
+			result, err := s.Sort("some-product", releases, concourse.SortByLastUpdated)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(sorted))
+
+			Expect(fakeSorter.SortByLastUpdatedCallCount()).To(Equal(1))
+		})
+
+		It("sorts by release date, newest first, with undated releases last", func() {
+			result, err := s.Sort("some-product", releases, concourse.SortByReleaseDate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal([]pivnet.Release{releases[1], releases[3], releases[0], releases[2]}))
+		})
+
+		Context("when sorting returns an error", func() {
+			BeforeEach(func() {
+				fakeSorter.SortBySemverReturns(nil, fmt.Errorf("some semver error"))
+			})
+
+			It("returns the error", func() {
+				_, err := s.Sort("some-product", releases, concourse.SortBySemver)
+				Expect(err).To(MatchError("some semver error"))
+			})
+		})
+	})
+
+	Describe("PivnetSortBy", func() {
+		It("maps each sort by to the closest pivnet-resource sort by", func() {
+			Expect(sorting.PivnetSortBy("")).To(Equal(pivnetconcourse.SortBy("")))
+			Expect(sorting.PivnetSortBy(concourse.SortByNone)).To(Equal(pivnetconcourse.SortByNone))
+			Expect(sorting.PivnetSortBy(concourse.SortBySemver)).To(Equal(pivnetconcourse.SortBySemver))
+			Expect(sorting.PivnetSortBy(concourse.SortByLastUpdated)).To(Equal(pivnetconcourse.SortByLastUpdated))
+			Expect(sorting.PivnetSortBy(concourse.SortByReleaseDate)).To(Equal(pivnetconcourse.SortByNone))
+			Expect(sorting.PivnetSortBy(concourse.SortByStemcellFirst)).To(Equal(pivnetconcourse.SortBySemver))
+		})
+	})
+
+	Describe("PublishedAt", func() {
+		It("prefers the release date", func() {
+			t, err := sorting.PublishedAt(pivnet.Release{ReleaseDate: "2023-01-01", UpdatedAt: "2023-03-01T10:00:00Z"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t).To(Equal(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("falls back to when the release was last updated", func() {
+			t, err := sorting.PublishedAt(pivnet.Release{UpdatedAt: "2023-03-01T10:00:00Z"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t).To(Equal(time.Date(2023, time.March, 1, 10, 0, 0, 0, time.UTC)))
+		})
+
+		It("returns an error when neither are set", func() {
+			_, err := sorting.PublishedAt(pivnet.Release{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package suggest
+
+import (
+	"sort"
+	"strings"
+)
+
+// Closest : returns up to limit candidates which are close to the target by edit distance, closest first. Candidates
+// further than a third of the target's length, or two edits for short targets, are not considered close.
+func Closest(target string, candidates []string, limit int) []string {
+	maxDistance := len(target) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	type suggestion struct {
+		candidate string
+		distance  int
+	}
+
+	var suggestions []suggestion
+	for _, candidate := range candidates {
+		d := Distance(strings.ToLower(target), strings.ToLower(candidate))
+		if d <= maxDistance {
+			suggestions = append(suggestions, suggestion{candidate: candidate, distance: d})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		return suggestions[i].candidate < suggestions[j].candidate
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	closest := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		closest[i] = s.candidate
+	}
+	return closest
+}
+
+// Distance : the Levenshtein edit distance between a and b
+func Distance(a string, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
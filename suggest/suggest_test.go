@@ -0,0 +1,52 @@
+package suggest_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/suggest"
+)
+
+var _ = Describe("Suggest", func() {
+	Describe("Distance", func() {
+		It("counts insertions, deletions and substitutions", func() {
+			Expect(suggest.Distance("", "")).To(Equal(0))
+			Expect(suggest.Distance("abc", "abc")).To(Equal(0))
+			Expect(suggest.Distance("", "abc")).To(Equal(3))
+			Expect(suggest.Distance("kitten", "sitting")).To(Equal(3))
+			Expect(suggest.Distance("stemcells-ubuntu-jammy", "stemcells-ubuntu-jamy")).To(Equal(1))
+		})
+	})
+
+	Describe("Closest", func() {
+		var candidates []string
+
+		BeforeEach(func() {
+			candidates = []string{
+				"elastic-runtime",
+				"stemcells-ubuntu-jammy",
+				"stemcells-ubuntu-xenial",
+				"p-redis",
+				"p-rabbitmq",
+			}
+		})
+
+		It("returns the closest candidates first", func() {
+			Expect(suggest.Closest("stemcells-ubuntu-jamy", candidates, 3)).To(HaveLen(2))
+			Expect(suggest.Closest("stemcells-ubuntu-jamy", candidates, 3)[0]).To(Equal("stemcells-ubuntu-jammy"))
+			Expect(suggest.Closest("p-redi", candidates, 3)).To(Equal([]string{"p-redis"}))
+		})
+
+		It("ignores case", func() {
+			Expect(suggest.Closest("Elastic-Runtime", candidates, 3)).To(Equal([]string{"elastic-runtime"}))
+		})
+
+		It("limits the number of candidates", func() {
+			Expect(suggest.Closest("stemcells-ubuntu-jamy", candidates, 1)).To(Equal([]string{"stemcells-ubuntu-jammy"}))
+		})
+
+		It("returns nothing when no candidate is close", func() {
+			Expect(suggest.Closest("something-else", candidates, 3)).To(BeEmpty())
+		})
+	})
+})
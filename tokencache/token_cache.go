@@ -0,0 +1,238 @@
+package tokencache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/pivotal-cf/go-pivnet/v7/logger"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/auth"
+)
+
+const (
+	// expiryMargin is how long before it expires that a cached access token is exchanged again, so that it cannot
+	// expire mid check
+	expiryMargin = 5 * time.Minute
+
+	keyContext = "pivnet-product-stemcell-resource access token cache"
+)
+
+//go:generate counterfeiter --fake-name FakeClock . clock
+type clock interface {
+	Now() time.Time
+}
+
+// TokenCache : caches the access tokens exchanged for UAA refresh tokens in files encrypted with the refresh token,
+// so that repeated invocations on the same worker do not exchange them again
+type TokenCache struct {
+	dir    string
+	clock  clock
+	logger logger.Logger
+}
+
+// NewTokenCache : Creates an instance of the TokenCache storing access tokens in dir
+func NewTokenCache(dir string, clock clock, logger logger.Logger) *TokenCache {
+	return &TokenCache{
+		dir:    dir,
+		clock:  clock,
+		logger: logger,
+	}
+}
+
+// DefaultDir : the directory access tokens are cached in when none is configured
+func DefaultDir() string {
+	return filepath.Join(os.TempDir(), "pivnet-product-stemcell-resource", "access-tokens")
+}
+
+// AccessTokenService : wraps the token service of the refresh token and endpoint, returning the cached access token
+// until it is about to expire. Legacy API tokens are returned as is.
+func (c *TokenCache) AccessTokenService(refreshToken string, endpoint string, tokens pivnet.AccessTokenService) pivnet.AccessTokenService {
+	if auth.IsLegacyToken(refreshToken) {
+		return tokens
+	}
+
+	keyHash := sha256.Sum256([]byte(keyContext + "\x00" + refreshToken))
+	nameHash := sha256.Sum256([]byte(endpoint + "\x00" + refreshToken))
+
+	return &cachedAccessTokenService{
+		cache:  c,
+		tokens: tokens,
+		path:   filepath.Join(c.dir, hex.EncodeToString(nameHash[:])),
+		key:    keyHash[:],
+	}
+}
+
+type cachedToken struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type cachedAccessTokenService struct {
+	cache  *TokenCache
+	tokens pivnet.AccessTokenService
+	path   string
+	key    []byte
+
+	mu     sync.Mutex
+	cached *cachedToken
+}
+
+func (s *cachedAccessTokenService) AccessToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.valid(s.cached) {
+		return s.cached.AccessToken, nil
+	}
+
+	cached, err := s.read()
+	if err != nil {
+		s.cache.logger.Debug(fmt.Sprintf("Cannot read cached access token: %s", err))
+	} else if s.valid(cached) {
+		s.cache.logger.Debug("Using cached access token")
+		s.cached = cached
+		return cached.AccessToken, nil
+	}
+
+	accessToken, err := s.tokens.AccessToken()
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt, ok := expiry(accessToken)
+	if !ok {
+		s.cache.logger.Debug("Not caching access token as its expiry is unknown")
+		return accessToken, nil
+	}
+
+	s.cached = &cachedToken{AccessToken: accessToken, ExpiresAt: expiresAt}
+
+	err = s.write(s.cached)
+	if err != nil {
+		s.cache.logger.Debug(fmt.Sprintf("Cannot cache access token: %s", err))
+	}
+
+	return accessToken, nil
+}
+
+func (s *cachedAccessTokenService) valid(cached *cachedToken) bool {
+	return cached != nil && s.cache.clock.Now().Add(expiryMargin).Before(cached.ExpiresAt)
+}
+
+func (s *cachedAccessTokenService) read() (*cachedToken, error) {
+	contents, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := s.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(contents) < gcm.NonceSize() {
+		return nil, fmt.Errorf("cached access token is truncated")
+	}
+
+	plaintext, err := gcm.Open(nil, contents[:gcm.NonceSize()], contents[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt cached access token: %s", err)
+	}
+
+	var cached cachedToken
+	err = json.Unmarshal(plaintext, &cached)
+	if err != nil {
+		return nil, err
+	}
+	return &cached, nil
+}
+
+func (s *cachedAccessTokenService) write(cached *cachedToken) error {
+	plaintext, err := json.Marshal(cached)
+	if err != nil {
+		// Untested as it is too hard to force json.Marshal to return an error
+		return err
+	}
+
+	gcm, err := s.gcm()
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(s.cache.dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	// written to a temporary file first so that concurrent checks never read a partial file
+	file, err := ioutil.TempFile(s.cache.dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(gcm.Seal(nonce, nonce, plaintext, nil))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path)
+}
+
+func (s *cachedAccessTokenService) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// expiry reads the expiry of a JWT access token without verifying it, as it is only used to decide when to exchange
+// the refresh token again
+func expiry(accessToken string) (time.Time, bool) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(claims.Exp, 0), true
+}
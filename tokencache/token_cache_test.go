@@ -0,0 +1,176 @@
+package tokencache_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet/v7"
+	"github.com/pivotal-cf/go-pivnet/v7/go-pivnetfakes"
+	"github.com/pivotal-cf/go-pivnet/v7/logshim"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/tokencache"
+	"github.com/shanman190/pivnet-product-stemcell-resource/tokencache/tokencachefakes"
+)
+
+const (
+	refreshToken = "some-uaa-refresh-token-longer-than-a-legacy-token"
+	endpoint     = "https://network.pivotal.io"
+)
+
+var _ = Describe("TokenCache", func() {
+	var (
+		dir       string
+		fakeClock *tokencachefakes.FakeClock
+		now       time.Time
+
+		fakeAccessTokenService *gopivnetfakes.FakeAccessTokenService
+		accessToken            string
+
+		cache *tokencache.TokenCache
+	)
+
+	newService := func() pivnet.AccessTokenService {
+		return cache.AccessTokenService(refreshToken, endpoint, fakeAccessTokenService)
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "token-cache")
+		Expect(err).NotTo(HaveOccurred())
+
+		now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		fakeClock = &tokencachefakes.FakeClock{}
+		fakeClock.NowReturns(now)
+
+		accessToken = jwt(now.Add(time.Hour))
+		fakeAccessTokenService = &gopivnetfakes.FakeAccessTokenService{}
+		fakeAccessTokenService.AccessTokenReturns(accessToken, nil)
+
+		logger := log.New(GinkgoWriter, "", log.LstdFlags)
+		cache = tokencache.NewTokenCache(filepath.Join(dir, "cache"), fakeClock, logshim.NewLogShim(logger, logger, true))
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("exchanges the refresh token once per invocation", func() {
+		service := newService()
+
+		Expect(service.AccessToken()).To(Equal(accessToken))
+		Expect(service.AccessToken()).To(Equal(accessToken))
+
+		Expect(fakeAccessTokenService.AccessTokenCallCount()).To(Equal(1))
+	})
+
+	It("reuses the access token in later invocations", func() {
+		Expect(newService().AccessToken()).To(Equal(accessToken))
+		Expect(newService().AccessToken()).To(Equal(accessToken))
+
+		Expect(fakeAccessTokenService.AccessTokenCallCount()).To(Equal(1))
+	})
+
+	It("encrypts the cached access token", func() {
+		Expect(newService().AccessToken()).To(Equal(accessToken))
+
+		files, err := filepath.Glob(filepath.Join(dir, "cache", "*"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+
+		Expect(filepath.Base(files[0])).NotTo(ContainSubstring(refreshToken))
+
+		contents, err := ioutil.ReadFile(files[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).NotTo(ContainSubstring(accessToken))
+
+		info, err := os.Stat(files[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	Context("when the access token is about to expire", func() {
+		It("exchanges the refresh token again", func() {
+			Expect(newService().AccessToken()).To(Equal(accessToken))
+
+			fakeClock.NowReturns(now.Add(58 * time.Minute))
+			newAccessToken := jwt(now.Add(2 * time.Hour))
+			fakeAccessTokenService.AccessTokenReturns(newAccessToken, nil)
+
+			Expect(newService().AccessToken()).To(Equal(newAccessToken))
+			Expect(fakeAccessTokenService.AccessTokenCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when the refresh token or endpoint differ", func() {
+		It("does not share access tokens", func() {
+			Expect(newService().AccessToken()).To(Equal(accessToken))
+
+			_, err := cache.AccessTokenService(refreshToken, "https://other.example.com", fakeAccessTokenService).AccessToken()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = cache.AccessTokenService(refreshToken+"-other", endpoint, fakeAccessTokenService).AccessToken()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeAccessTokenService.AccessTokenCallCount()).To(Equal(3))
+		})
+	})
+
+	Context("when the cached access token is corrupted", func() {
+		It("exchanges the refresh token again", func() {
+			Expect(newService().AccessToken()).To(Equal(accessToken))
+
+			files, err := filepath.Glob(filepath.Join(dir, "cache", "*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(files[0], []byte("corrupted contents of the cache"), 0600)).To(Succeed())
+
+			Expect(newService().AccessToken()).To(Equal(accessToken))
+			Expect(fakeAccessTokenService.AccessTokenCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when the expiry of the access token is unknown", func() {
+		BeforeEach(func() {
+			fakeAccessTokenService.AccessTokenReturns("some-opaque-access-token-longer-than-a-legacy-token", nil)
+		})
+
+		It("does not cache it", func() {
+			Expect(newService().AccessToken()).To(Equal("some-opaque-access-token-longer-than-a-legacy-token"))
+			Expect(newService().AccessToken()).To(Equal("some-opaque-access-token-longer-than-a-legacy-token"))
+
+			Expect(fakeAccessTokenService.AccessTokenCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when the token is a legacy API token", func() {
+		It("returns the token service as is", func() {
+			Expect(cache.AccessTokenService("some-legacy-token", endpoint, fakeAccessTokenService)).To(BeIdenticalTo(fakeAccessTokenService))
+		})
+	})
+
+	Context("when the refresh token cannot be exchanged", func() {
+		BeforeEach(func() {
+			fakeAccessTokenService.AccessTokenReturns("", errors.New("some error"))
+		})
+
+		It("returns the error", func() {
+			_, err := newService().AccessToken()
+			Expect(err).To(MatchError("some error"))
+		})
+	})
+})
+
+func jwt(expiresAt time.Time) string {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	header := encode([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload := encode([]byte(fmt.Sprintf(`{"sub":"some-user","exp":%d}`, expiresAt.Unix())))
+	return fmt.Sprintf("%s.%s.%s", header, payload, encode([]byte("some-signature")))
+}
@@ -0,0 +1,13 @@
+package tokencache_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestTokencache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tokencache Suite")
+}
@@ -0,0 +1,120 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/interrupt"
+)
+
+// TLSConfig : the TLS configuration of the source, trusting the system certificate authorities along with any
+// ca_certs, and presenting the client_cert when one is provided
+func TLSConfig(source concourse.Source) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: source.SkipSSLValidation,
+	}
+
+	if len(source.CACerts) > 0 {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		for i, caCert := range source.CACerts {
+			if !rootCAs.AppendCertsFromPEM([]byte(caCert)) {
+				return nil, fmt.Errorf("ca_certs[%d] does not contain a PEM encoded certificate", i)
+			}
+		}
+
+		config.RootCAs = rootCAs
+	}
+
+	if source.ClientCert != "" && source.ClientKey != "" {
+		certificate, err := tls.X509KeyPair([]byte(source.ClientCert), []byte(source.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client_cert and client_key: %s", err)
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
+
+// NewTransport : Creates the HTTP transport used for every request to Pivotal Network and for downloads
+func NewTransport(source concourse.Source) (*http.Transport, error) {
+	config, err := TLSConfig(source)
+	if err != nil {
+		return nil, err
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = config
+	t.Proxy = proxy(source)
+
+	return t, nil
+}
+
+// ProxyURL : parses a proxy as it will be used, where a proxy without a scheme such as 'proxy.example.com:3128'
+// is assumed to be an HTTP proxy
+func ProxyURL(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+		return nil, fmt.Errorf("cannot parse proxy")
+	}
+	return u, nil
+}
+
+// proxy : selects the proxy of each request from the source, falling back to the environment for anything unset
+func proxy(source concourse.Source) func(*http.Request) (*url.URL, error) {
+	config := httpproxy.FromEnvironment()
+	if source.HTTPProxy != "" {
+		config.HTTPProxy = source.HTTPProxy
+	}
+	if source.HTTPSProxy != "" {
+		config.HTTPSProxy = source.HTTPSProxy
+	}
+	if source.NoProxy != "" {
+		config.NoProxy = source.NoProxy
+	}
+
+	proxyFunc := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// WithContext : wraps the transport so that requests are cancelled when ctx ends, go-pivnet making every request
+// without a context of its own
+func WithContext(ctx context.Context, transport http.RoundTripper) http.RoundTripper {
+	return contextRoundTripper{ctx: ctx, transport: transport}
+}
+
+type contextRoundTripper struct {
+	ctx       context.Context
+	transport http.RoundTripper
+}
+
+func (t contextRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Context().Done() == nil {
+		req = req.WithContext(t.ctx)
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil && t.ctx.Err() != nil {
+		return nil, fmt.Errorf("%s %s %s", req.Method, req.URL.Path, interrupt.Cause(t.ctx))
+	}
+	return resp, err
+}
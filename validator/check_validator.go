@@ -2,10 +2,19 @@ package validator
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/versions"
 )
 
+var availabilities = []string{
+	"All Users",
+	"Selected User Groups Only",
+	"Admins Only",
+}
+
 // CheckValidator : validates that a check request is valid before processing can continue
 type CheckValidator struct {
 	input concourse.CheckRequest
@@ -18,18 +27,108 @@ func NewCheckValidator(input concourse.CheckRequest) *CheckValidator {
 	}
 }
 
-// Validate : validate the check request
+// Validate : validate the check request, reporting every problem found
 func (v CheckValidator) Validate() error {
-	if v.input.Source.APIToken == "" {
-		return fmt.Errorf("%s must be provided", "api_token")
+	var problems []string
+
+	problems = append(problems, validateAPIToken(v.input.Source)...)
+
+	mode := v.input.Source.Mode
+	if mode != "" && mode != concourse.ModeForward && mode != concourse.ModeReverse {
+		problems = append(problems, fmt.Sprintf("%s must be one of: ['%s', '%s']", "mode", concourse.ModeForward, concourse.ModeReverse))
 	}
 
-	if v.input.Source.ProductSlug == "" {
-		return fmt.Errorf("%s must be provided", "product_slug")
+	track := v.input.Source.Track
+	if track != "" && track != concourse.TrackPerMinor && track != concourse.TrackPerMajor {
+		problems = append(problems, fmt.Sprintf("%s must be one of: ['%s', '%s']", "track", concourse.TrackPerMinor, concourse.TrackPerMajor))
 	}
 
-	if v.input.Source.StemcellSlug == "" {
-		return fmt.Errorf("%s must be provided", "stemcell_slug")
+	if track != "" && mode == concourse.ModeReverse {
+		problems = append(problems, fmt.Sprintf("%s cannot be used with %s: '%s'", "track", "mode", concourse.ModeReverse))
+	}
+
+	if mode == concourse.ModeReverse {
+		if len(v.input.Source.TrackedProductSlugs()) == 0 {
+			problems = append(problems, fmt.Sprintf("%s or %s must be provided", "product_slug", "product_slugs"))
+		}
+	} else if v.input.Source.ProductSlug == "" {
+		problems = append(problems, fmt.Sprintf("%s must be provided", "product_slug"))
+	}
+
+	if mode == concourse.ModeReverse && v.input.Source.StemcellSlug == "" && v.input.Source.DependencySlug == "" {
+		problems = append(problems, fmt.Sprintf("%s or %s must be provided when %s is '%s'", "stemcell_slug", "dependency_slug", "mode", concourse.ModeReverse))
+	}
+
+	if v.input.Source.StemcellSlug != "" && v.input.Source.DependencySlug != "" {
+		problems = append(problems, fmt.Sprintf("only one of %s or %s may be provided", "stemcell_slug", "dependency_slug"))
+	}
+
+	for _, productVersion := range v.input.Source.ProductVersion {
+		_, err := regexp.Compile(productVersion)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be a valid regular expression: '%s': %s", "product_version", productVersion, err))
+		}
+	}
+
+	if len(v.input.Source.ProductVersion) > 0 && v.input.Source.ProductVersionConstraint != "" {
+		problems = append(problems, fmt.Sprintf("only one of %s or %s may be provided", "product_version", "product_version_constraint"))
+	}
+
+	if v.input.Source.ProductVersionConstraint != "" {
+		_, err := versions.NewConstraint(v.input.Source.ProductVersionConstraint)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be a valid semver range: %s", "product_version_constraint", err))
+		}
+	}
+
+	problems = append(problems, validateSortBy(v.input.Source.SortBy)...)
+	problems = append(problems, validateTransport(v.input.Source)...)
+	problems = append(problems, validateTimeout(v.input.Source.Timeout)...)
+	problems = append(problems, validateLogRetention(v.input.Source.LogRetention)...)
+	problems = append(problems, validateLogLevel(v.input.Source.LogLevel)...)
+
+	for _, availability := range v.input.Source.Availability {
+		if !containsStringFold(availabilities, availability) {
+			problems = append(problems, fmt.Sprintf("%s must be one of: ['%s']", "availability", strings.Join(availabilities, "', '")))
+			break
+		}
+	}
+
+	_, err := concourse.ParseReleaseAge(v.input.Source.MinReleaseAge.Product)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("%s must be a duration such as 72h or 3d: %s", "min_release_age.product", err))
+	}
+
+	_, err = concourse.ParseReleaseAge(v.input.Source.MinReleaseAge.Stemcell)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("%s must be a duration such as 72h or 3d: %s", "min_release_age.stemcell", err))
+	}
+
+	_, err = versions.NewExclusions(v.input.Source.ExcludeProductVersions)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("%s must contain exact versions, regular expressions or semver ranges: %s", "exclude_product_versions", err))
+	}
+
+	_, err = versions.NewExclusions(v.input.Source.ExcludeStemcellVersions)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("%s must contain exact versions, regular expressions or semver ranges: %s", "exclude_stemcell_versions", err))
+	}
+
+	if v.input.Source.OpsManagerVersion != "" {
+		_, err := versions.NewConstraint(v.input.Source.OpsManagerVersion)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be a valid semver version or range: %s", "opsman_version", err))
+		}
+	}
+
+	return newError(problems)
+}
+
+func containsStringFold(values []string, str string) bool {
+	for _, v := range values {
+		if strings.EqualFold(str, v) {
+			return true
+		}
 	}
-	return nil
+	return false
 }
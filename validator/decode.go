@@ -0,0 +1,202 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/suggest"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// Decode : decodes a request from JSON, reporting every key which does not correspond to a field of the request with
+// a suggestion of the closest known key and every value which cannot be decoded into its field. The rest of the
+// request is still decoded so that its problems can be reported alongside those found by validating it
+func Decode(r io.Reader, v interface{}) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw interface{}
+	err = decoder.Decode(&raw)
+	if err != nil {
+		return err
+	}
+
+	problems, ok := decodeProblems("", raw, reflect.TypeOf(v))
+	if ok {
+		data, err = json.Marshal(raw)
+		if err != nil {
+			return err
+		}
+
+		err = json.Unmarshal(data, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return newError(problems)
+}
+
+// decodeProblems walks the decoded JSON value alongside the type it will be decoded into, reporting keys which
+// encoding/json would otherwise silently ignore and values which it would fail to decode. Offending keys are removed
+// from the value, and false is returned when the value itself cannot be decoded
+func decodeProblems(path string, value interface{}, t reflect.Type) ([]string, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return valueProblems(path, value, t)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if t.Kind() != reflect.Struct {
+			return valueProblems(path, value, t)
+		}
+
+		fields := jsonFields(t)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var problems []string
+		for _, key := range keys {
+			field, ok := lookupField(fields, key)
+			if !ok {
+				problems = append(problems, unknownKeyProblem(joinPath(path, key), key, names))
+				delete(v, key)
+				continue
+			}
+
+			fieldProblems, ok := decodeProblems(joinPath(path, key), v[key], field)
+			problems = append(problems, fieldProblems...)
+			if !ok {
+				delete(v, key)
+			}
+		}
+		return problems, true
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return valueProblems(path, value, t)
+		}
+
+		var problems []string
+		valid := true
+		for i, element := range v {
+			elementProblems, ok := decodeProblems(fmt.Sprintf("%s[%d]", path, i), element, t.Elem())
+			problems = append(problems, elementProblems...)
+			valid = valid && ok
+		}
+		return problems, valid
+	default:
+		return valueProblems(path, value, t)
+	}
+}
+
+// valueProblems decodes the value into the type, reporting its error for the key as encoding/json errors cannot name
+// the key themselves
+func valueProblems(path string, value interface{}, t reflect.Type) ([]string, bool) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return []string{fmt.Sprintf("%s cannot be decoded: %s", path, err)}, false
+	}
+
+	err = json.Unmarshal(data, reflect.New(t).Interface())
+	if _, ok := err.(*json.UnmarshalTypeError); ok {
+		return []string{fmt.Sprintf("%s must be %s", path, kindName(t))}, false
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("%s %s", path, err)}, false
+	}
+	return nil, true
+}
+
+// kindName describes the JSON value a type is decoded from
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	default:
+		return "an object"
+	}
+}
+
+// jsonFields returns the types of the exported fields of the struct by the key encoding/json decodes them from
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+			name = tagName
+		}
+
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// lookupField finds the field for the key, ignoring case as encoding/json does
+func lookupField(fields map[string]reflect.Type, key string) (reflect.Type, bool) {
+	if field, ok := fields[key]; ok {
+		return field, true
+	}
+
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+func unknownKeyProblem(path string, key string, names []string) string {
+	closest := suggest.Closest(key, names, 1)
+	if len(closest) == 0 {
+		return fmt.Sprintf("unknown key: '%s'", path)
+	}
+	return fmt.Sprintf("unknown key: '%s', did you mean '%s'?", path, closest[0])
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
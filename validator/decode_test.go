@@ -0,0 +1,111 @@
+package validator_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/validator"
+)
+
+var _ = Describe("Decode", func() {
+	It("decodes a request with known keys", func() {
+		var input concourse.InRequest
+		err := validator.Decode(strings.NewReader(`{
+			"source": {
+				"api_token": "some-api-token",
+				"product_slug": "some-product",
+				"release_type": ["Major Release"],
+				"min_release_age": {"product": "3d"}
+			},
+			"version": {"product_version": "1.2.3", "stemcell_version": "621.99"},
+			"params": {"globs": ["*.pivotal"]}
+		}`), &input)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(input.Source.ProductSlug).To(Equal("some-product"))
+		Expect(input.Source.ReleaseType).To(Equal(concourse.StringList{"Major Release"}))
+		Expect(input.Source.MinReleaseAge.Product).To(Equal("3d"))
+		Expect(input.Params.Globs).To(Equal([]string{"*.pivotal"}))
+	})
+
+	It("accepts keys which differ only in case, as encoding/json does", func() {
+		var input concourse.CheckRequest
+		err := validator.Decode(strings.NewReader(`{"source": {"Product_Slug": "some-product"}}`), &input)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(input.Source.ProductSlug).To(Equal("some-product"))
+	})
+
+	It("rejects every unknown key with a suggestion", func() {
+		var input concourse.CheckRequest
+		err := validator.Decode(strings.NewReader(`{
+			"source": {
+				"stemcel_slug": "stemcells-ubuntu-jammy",
+				"skip_ssl_validation": true,
+				"min_release_age": {"stemcel": "3d"},
+				"zzzzzzzzzz": true
+			}
+		}`), &input)
+		Expect(err).To(HaveOccurred())
+
+		Expect(err).To(BeAssignableToTypeOf(validator.Error{}))
+		Expect(err.(validator.Error).Problems).To(Equal([]string{
+			"unknown key: 'source.min_release_age.stemcel', did you mean 'stemcell'?",
+			"unknown key: 'source.skip_ssl_validation', did you mean 'skip_ssl_verification'?",
+			"unknown key: 'source.stemcel_slug', did you mean 'stemcell_slug'?",
+			"unknown key: 'source.zzzzzzzzzz'",
+		}))
+	})
+
+	It("names the keys of string lists which are neither a string nor a list of strings", func() {
+		var input concourse.CheckRequest
+		err := validator.Decode(strings.NewReader(`{
+			"source": {
+				"release_type": 1,
+				"product_version": {"version": "1.2.3"}
+			}
+		}`), &input)
+		Expect(err).To(HaveOccurred())
+
+		Expect(err).To(BeAssignableToTypeOf(validator.Error{}))
+		Expect(err.(validator.Error).Problems).To(Equal([]string{
+			"source.product_version must be a string or a list of strings",
+			"source.release_type must be a string or a list of strings",
+		}))
+	})
+
+	It("reports values of the wrong type alongside unknown keys and decodes the rest", func() {
+		var input concourse.CheckRequest
+		err := validator.Decode(strings.NewReader(`{
+			"source": {
+				"product_slug": "some-product",
+				"verbose": "yes",
+				"log_retention": "7",
+				"availability": ["All Users", 1],
+				"sort_by": 1,
+				"stemcel_slug": "stemcells-ubuntu-jammy"
+			}
+		}`), &input)
+		Expect(err).To(HaveOccurred())
+
+		Expect(err).To(BeAssignableToTypeOf(validator.Error{}))
+		Expect(err.(validator.Error).Problems).To(Equal([]string{
+			"source.availability[1] must be a string",
+			"source.log_retention must be a number",
+			"source.sort_by must be a string",
+			"unknown key: 'source.stemcel_slug', did you mean 'stemcell_slug'?",
+			"source.verbose must be a boolean",
+		}))
+		Expect(input.Source.ProductSlug).To(Equal("some-product"))
+		Expect(input.Source.Availability).To(BeEmpty())
+	})
+
+	It("returns an error for invalid JSON", func() {
+		var input concourse.CheckRequest
+		err := validator.Decode(strings.NewReader(`{"source": `), &input)
+		Expect(err).To(HaveOccurred())
+	})
+})
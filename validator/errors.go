@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/concourse"
+	"github.com/shanman190/pivnet-product-stemcell-resource/transport"
+)
+
+// Error : every problem found with a request, so that they can all be fixed at once
+type Error struct {
+	Problems []string
+}
+
+func newError(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return Error{Problems: problems}
+}
+
+// Error : a single problem on its own, otherwise a list of the problems
+func (e Error) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0]
+	}
+	return fmt.Sprintf("found %d problems:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Join : merges the problems of the errors into a single Error, returning the first error which is not an Error as it
+// prevents the others from being reported
+func Join(errs ...error) error {
+	var problems []string
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		validationErr, ok := err.(Error)
+		if !ok {
+			return err
+		}
+		problems = append(problems, validationErr.Problems...)
+	}
+	return newError(problems)
+}
+
+func validateAPIToken(source concourse.Source) []string {
+	provided := 0
+	for _, token := range []string{source.APIToken, source.APITokenFile, source.APITokenEnv} {
+		if token != "" {
+			provided++
+		}
+	}
+
+	switch {
+	case provided == 0:
+		return []string{fmt.Sprintf("%s, %s or %s must be provided", "api_token", "api_token_file", "api_token_env")}
+	case provided > 1:
+		return []string{fmt.Sprintf("only one of %s, %s or %s may be provided", "api_token", "api_token_file", "api_token_env")}
+	default:
+		return nil
+	}
+}
+
+func validateTimeout(timeout string) []string {
+	_, err := concourse.ParseTimeout(timeout)
+	if err != nil {
+		return []string{fmt.Sprintf("%s must be a positive duration such as 10m: %s", "timeout", err)}
+	}
+	return nil
+}
+
+func validateLogRetention(retention int) []string {
+	if retention < 0 {
+		return []string{fmt.Sprintf("%s must not be negative: %d", "log_retention", retention)}
+	}
+	return nil
+}
+
+func validateLogLevel(logLevel concourse.LogLevel) []string {
+	if logLevel == "" {
+		return nil
+	}
+
+	choices := make([]string, len(concourse.LogLevels))
+	for i, choice := range concourse.LogLevels {
+		if logLevel == choice {
+			return nil
+		}
+		choices[i] = string(choice)
+	}
+	return []string{fmt.Sprintf("%s must be one of: ['%s']", "log_level", strings.Join(choices, "', '"))}
+}
+
+func validateSortBy(sortBy concourse.SortBy) []string {
+	if sortBy.Valid() {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s must be one of: %s", "sort_by", concourse.SortByChoices())}
+}
+
+func validateTransport(source concourse.Source) []string {
+	var problems []string
+
+	if (source.ClientCert == "") != (source.ClientKey == "") {
+		problems = append(problems, fmt.Sprintf("%s and %s must be provided together", "client_cert", "client_key"))
+	} else if _, err := transport.TLSConfig(source); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	// the proxies are left out of the problems as they may contain credentials
+	proxies := []struct {
+		name  string
+		proxy string
+	}{
+		{"http_proxy", source.HTTPProxy},
+		{"https_proxy", source.HTTPSProxy},
+	}
+	for _, p := range proxies {
+		if p.proxy == "" {
+			continue
+		}
+		if _, err := transport.ProxyURL(p.proxy); err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be a URL such as 'http://proxy.example.com:3128'", p.name))
+		}
+	}
+
+	return problems
+}
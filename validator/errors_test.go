@@ -0,0 +1,46 @@
+package validator_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/validator"
+)
+
+var _ = Describe("Error", func() {
+	It("reports a single problem on its own", func() {
+		err := validator.Error{Problems: []string{"api_token must be provided"}}
+		Expect(err.Error()).To(Equal("api_token must be provided"))
+	})
+
+	It("lists multiple problems", func() {
+		err := validator.Error{Problems: []string{"api_token must be provided", "product_slug must be provided"}}
+		Expect(err.Error()).To(Equal("found 2 problems:\n  - api_token must be provided\n  - product_slug must be provided"))
+	})
+})
+
+var _ = Describe("Join", func() {
+	It("merges the problems of every error", func() {
+		err := validator.Join(
+			validator.Error{Problems: []string{"unknown key: 'source.stemcel_slug', did you mean 'stemcell_slug'?"}},
+			nil,
+			validator.Error{Problems: []string{"api_token must be provided"}},
+		)
+		Expect(err).To(Equal(validator.Error{Problems: []string{
+			"unknown key: 'source.stemcel_slug', did you mean 'stemcell_slug'?",
+			"api_token must be provided",
+		}}))
+	})
+
+	It("returns nil without problems", func() {
+		Expect(validator.Join(nil, nil)).To(BeNil())
+	})
+
+	It("returns other errors on their own", func() {
+		other := errors.New("some error")
+		err := validator.Join(validator.Error{Problems: []string{"api_token must be provided"}}, other)
+		Expect(err).To(Equal(other))
+	})
+})
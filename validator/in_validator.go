@@ -18,27 +18,54 @@ func NewInValidator(input concourse.InRequest) *InValidator {
 	}
 }
 
-// Validate : validate the in request
+// Validate : validate the in request, reporting every problem found
 func (v InValidator) Validate() error {
-	if v.input.Source.APIToken == "" {
-		return fmt.Errorf("%s must be provided", "api_token")
+	var problems []string
+
+	problems = append(problems, validateAPIToken(v.input.Source)...)
+
+	mode := v.input.Source.Mode
+	if mode != "" && mode != concourse.ModeForward && mode != concourse.ModeReverse {
+		problems = append(problems, fmt.Sprintf("%s must be one of: ['%s', '%s']", "mode", concourse.ModeForward, concourse.ModeReverse))
+	}
+
+	if mode == concourse.ModeReverse {
+		if len(v.input.Source.TrackedProductSlugs()) == 0 {
+			problems = append(problems, fmt.Sprintf("%s or %s must be provided", "product_slug", "product_slugs"))
+		}
+	} else if v.input.Source.ProductSlug == "" {
+		problems = append(problems, fmt.Sprintf("%s must be provided", "product_slug"))
 	}
 
-	if v.input.Source.ProductSlug == "" {
-		return fmt.Errorf("%s must be provided", "product_slug")
+	if mode == concourse.ModeReverse && v.input.Source.StemcellSlug == "" && v.input.Source.DependencySlug == "" {
+		problems = append(problems, fmt.Sprintf("%s or %s must be provided when %s is '%s'", "stemcell_slug", "dependency_slug", "mode", concourse.ModeReverse))
+	} else if v.input.TrackedDependencySlug() == "" {
+		problems = append(problems, fmt.Sprintf("%s or %s must be provided, or detected by check", "stemcell_slug", "dependency_slug"))
 	}
 
-	if v.input.Source.StemcellSlug == "" {
-		return fmt.Errorf("%s must be provided", "stemcell_slug")
+	if v.input.Source.StemcellSlug != "" && v.input.Source.DependencySlug != "" {
+		problems = append(problems, fmt.Sprintf("only one of %s or %s may be provided", "stemcell_slug", "dependency_slug"))
+	}
+
+	problems = append(problems, validateSortBy(v.input.Source.SortBy)...)
+	problems = append(problems, validateTransport(v.input.Source)...)
+	problems = append(problems, validateTimeout(v.input.Source.Timeout)...)
+
+	if mode != concourse.ModeReverse && v.input.Version.ProductVersion == "" {
+		problems = append(problems, fmt.Sprintf("%s must be provided", "product_version"))
 	}
 
-	if v.input.Version.ProductVersion == "" {
-		return fmt.Errorf("%s must be provided", "product_version")
+	if mode == concourse.ModeReverse && v.input.Params.UpgradeFrom != "" {
+		problems = append(problems, fmt.Sprintf("%s cannot be provided when %s is '%s'", "upgrade_from", "mode", concourse.ModeReverse))
 	}
 
-	if v.input.Version.StemcellVersion == "" {
-		return fmt.Errorf("%s must be provided", "stemcell_version")
+	if v.input.Version.TrackedDependencyVersion() == "" {
+		if v.input.Source.DependencySlug != "" {
+			problems = append(problems, fmt.Sprintf("%s must be provided", "dependency_version"))
+		} else {
+			problems = append(problems, fmt.Sprintf("%s must be provided", "stemcell_version"))
+		}
 	}
 
-	return nil
+	return newError(problems)
 }
@@ -16,8 +16,10 @@ var _ = Describe("In Validator", func() {
 		apiToken        string
 		productSlug     string
 		stemcellSlug    string
+		dependencySlug  string
 		productVersion  string
 		stemcellVersion string
+		dependencyVersion string
 	)
 
 	BeforeEach(func() {
@@ -26,6 +28,8 @@ var _ = Describe("In Validator", func() {
 		stemcellSlug = "some-stemcellSlug"
 		productVersion = "some-product-version"
 		stemcellVersion = "some-stemcell-version"
+		dependencySlug = ""
+		dependencyVersion = ""
 	})
 
 	JustBeforeEach(func() {
@@ -34,11 +38,13 @@ var _ = Describe("In Validator", func() {
 				APIToken:     apiToken,
 				ProductSlug:  productSlug,
 				StemcellSlug: stemcellSlug,
+				DependencySlug: dependencySlug,
 			},
 			Params: concourse.InParams{},
 			Version: concourse.Version{
 				ProductVersion:  productVersion,
 				StemcellVersion: stemcellVersion,
+				DependencyVersion: dependencyVersion,
 			},
 		}
 
@@ -59,7 +65,7 @@ var _ = Describe("In Validator", func() {
 		It("returns an error", func() {
 			err := v.Validate()
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(MatchRegexp("api_token must be provided"))
+			Expect(err.Error()).To(Equal("api_token, api_token_file or api_token_env must be provided"))
 		})
 	})
 
@@ -92,6 +98,18 @@ var _ = Describe("In Validator", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(MatchRegexp(".*stemcell_slug.*provided"))
 		})
+
+		Context("when the version records a detected stemcell slug", func() {
+			JustBeforeEach(func() {
+				inRequest.Version.StemcellSlug = "stemcells-ubuntu-jammy"
+				v = validator.NewInValidator(inRequest)
+			})
+
+			It("returns without error", func() {
+				err := v.Validate()
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
 	})
 
 	Context("when no product version is provided", func() {
@@ -117,4 +135,87 @@ var _ = Describe("In Validator", func() {
 			Expect(err.Error()).To(MatchRegexp(".*stemcell_version.*provided"))
 		})
 	})
+	Context("when a dependency slug and version are provided instead of a stemcell slug and version", func() {
+		BeforeEach(func() {
+			stemcellSlug = ""
+			stemcellVersion = ""
+			dependencySlug = "some-dependencySlug"
+			dependencyVersion = "some-dependency-version"
+		})
+
+		It("returns without error", func() {
+			err := v.Validate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when no dependency version is provided", func() {
+			BeforeEach(func() {
+				dependencyVersion = ""
+			})
+
+			It("returns an error", func() {
+				err := v.Validate()
+				Expect(err).NotTo(BeNil())
+				Expect(err.Error()).To(MatchRegexp(".*dependency_version.*provided"))
+			})
+		})
+	})
+
+	Context("when both a stemcell slug and a dependency slug are provided", func() {
+		BeforeEach(func() {
+			dependencySlug = "some-dependencySlug"
+		})
+
+		It("returns an error", func() {
+			err := v.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("only one of stemcell_slug or dependency_slug"))
+		})
+	})
+	Context("when in reverse mode without a product version", func() {
+		BeforeEach(func() {
+			productVersion = ""
+		})
+
+		JustBeforeEach(func() {
+			inRequest.Source.Mode = concourse.ModeReverse
+			v = validator.NewInValidator(inRequest)
+		})
+
+		It("returns without error", func() {
+			err := v.Validate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+	Context("when in reverse mode with an upgrade_from param", func() {
+		JustBeforeEach(func() {
+			inRequest.Source.Mode = concourse.ModeReverse
+			inRequest.Params.UpgradeFrom = "1.2.3"
+			v = validator.NewInValidator(inRequest)
+		})
+
+		It("returns an error", func() {
+			err := v.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(MatchRegexp("upgrade_from cannot be provided when mode is 'reverse'"))
+		})
+	})
+
+	Context("when there are several problems", func() {
+		BeforeEach(func() {
+			apiToken = ""
+			productVersion = ""
+			stemcellVersion = ""
+		})
+
+		It("reports all of them", func() {
+			err := v.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(Equal(validator.Error{Problems: []string{
+				"api_token, api_token_file or api_token_env must be provided",
+				"product_version must be provided",
+				"stemcell_version must be provided",
+			}}))
+		})
+	})
 })
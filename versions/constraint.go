@@ -0,0 +1,216 @@
+package versions
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/pivotal-cf/go-pivnet/v7"
+)
+
+var (
+	tolerantVersionRegex = regexp.MustCompile(`(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+	partialVersionRegex  = regexp.MustCompile(`^v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?$`)
+	comparatorRegex      = regexp.MustCompile(`^(>=|<=|!=|==|>|<|=|~|\^)?\s*(\S+)$`)
+)
+
+// ParseTolerant : parses the first major[.minor[.patch]] found in a PivNet style version such as '2.10.3-build.4'
+// or 'Binary 1.0.11'. Anything after the patch version is ignored so that build suffixes do not affect comparisons.
+func ParseTolerant(version string) (semver.Version, error) {
+	match := tolerantVersionRegex.FindStringSubmatch(version)
+	if match == nil {
+		return semver.Version{}, fmt.Errorf("cannot find a semver version in '%s'", version)
+	}
+
+	var parts [3]uint64
+	for i, part := range match[1:] {
+		if part == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("cannot parse a semver version from '%s': %s", version, err)
+		}
+		parts[i] = n
+	}
+
+	return semver.Version{Major: parts[0], Minor: parts[1], Patch: parts[2]}, nil
+}
+
+// Constraint : matches versions against a semver range such as '>=2.10 <2.12', '~2.10' or '^2.10.3 || 3.x'
+type Constraint struct {
+	constraint  string
+	semverRange semver.Range
+}
+
+// NewConstraint : creates a Constraint from a semver range. Comparators separated by whitespace must all match,
+// and groups separated by '||' are alternatives. Partial versions such as '2.10' are accepted.
+func NewConstraint(constraint string) (Constraint, error) {
+	var semverRange semver.Range
+	for _, group := range strings.Split(constraint, "||") {
+		comparators := splitComparators(group)
+		if len(comparators) == 0 {
+			return Constraint{}, fmt.Errorf("invalid version constraint: '%s'", constraint)
+		}
+
+		var groupRange semver.Range
+		for _, comparator := range comparators {
+			r, err := parseComparator(comparator)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("invalid version constraint: '%s': %s", constraint, err)
+			}
+			if groupRange == nil {
+				groupRange = r
+			} else {
+				groupRange = groupRange.AND(r)
+			}
+		}
+
+		if semverRange == nil {
+			semverRange = groupRange
+		} else {
+			semverRange = semverRange.OR(groupRange)
+		}
+	}
+
+	return Constraint{constraint: constraint, semverRange: semverRange}, nil
+}
+
+// String : the constraint the Constraint was created from
+func (c Constraint) String() string {
+	return c.constraint
+}
+
+// Matches : whether the version, parsed tolerantly, satisfies the constraint
+func (c Constraint) Matches(version string) bool {
+	v, err := ParseTolerant(version)
+	if err != nil {
+		return false
+	}
+	return c.semverRange(v)
+}
+
+// Releases : the releases whose versions satisfy the constraint, in their original order
+func (c Constraint) Releases(releases []pivnet.Release) []pivnet.Release {
+	var matches []pivnet.Release
+	for _, release := range releases {
+		if c.Matches(release.Version) {
+			matches = append(matches, release)
+		}
+	}
+	return matches
+}
+
+// splitComparators : splits a group on whitespace, joining operators separated from their version such as '>= 2.10'
+func splitComparators(group string) []string {
+	var comparators []string
+	pending := ""
+	for _, field := range strings.Fields(group) {
+		if strings.Trim(field, "<>=!~^") == "" {
+			pending += field
+			continue
+		}
+		comparators = append(comparators, pending+field)
+		pending = ""
+	}
+	if pending != "" {
+		comparators = append(comparators, pending)
+	}
+	return comparators
+}
+
+func parseComparator(comparator string) (semver.Range, error) {
+	match := comparatorRegex.FindStringSubmatch(comparator)
+	if match == nil {
+		return nil, fmt.Errorf("cannot parse '%s'", comparator)
+	}
+	operator := match[1]
+
+	versionMatch := partialVersionRegex.FindStringSubmatch(match[2])
+	if versionMatch == nil {
+		return nil, fmt.Errorf("cannot parse version '%s'", match[2])
+	}
+
+	// precision is the number of leading version parts provided, wildcards ending the version
+	var parts [3]uint64
+	precision := 0
+	for _, part := range versionMatch[1:] {
+		if part == "" || strings.ContainsAny(part, "xX*") {
+			break
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse version '%s': %s", match[2], err)
+		}
+		parts[precision] = n
+		precision++
+	}
+
+	lower := semver.Version{Major: parts[0], Minor: parts[1], Patch: parts[2]}
+	upper := nextVersion(lower, precision)
+
+	switch operator {
+	case ">=":
+		return atLeast(lower), nil
+	case ">":
+		if precision == 3 {
+			return func(v semver.Version) bool { return v.GT(lower) }, nil
+		}
+		return atLeast(upper), nil
+	case "<":
+		return below(lower), nil
+	case "<=":
+		if precision == 3 {
+			return func(v semver.Version) bool { return v.LTE(lower) }, nil
+		}
+		return below(upper), nil
+	case "!=":
+		return func(v semver.Version) bool { return !within(lower, upper, precision)(v) }, nil
+	case "~":
+		if precision == 3 {
+			upper = nextVersion(lower, 2)
+		}
+		return within(lower, upper, precision), nil
+	case "^":
+		switch {
+		case lower.Major > 0 || precision < 2:
+			upper = nextVersion(lower, 1)
+		case lower.Minor > 0 || precision < 3:
+			upper = nextVersion(lower, 2)
+		}
+		return within(lower, upper, precision), nil
+	default:
+		return within(lower, upper, precision), nil
+	}
+}
+
+// nextVersion : the lowest version above all versions sharing the first precision parts of v
+func nextVersion(v semver.Version, precision int) semver.Version {
+	switch precision {
+	case 0:
+		return semver.Version{}
+	case 1:
+		return semver.Version{Major: v.Major + 1}
+	case 2:
+		return semver.Version{Major: v.Major, Minor: v.Minor + 1}
+	default:
+		return semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+}
+
+func atLeast(lower semver.Version) semver.Range {
+	return func(v semver.Version) bool { return v.GTE(lower) }
+}
+
+func below(upper semver.Version) semver.Range {
+	return func(v semver.Version) bool { return v.LT(upper) }
+}
+
+func within(lower, upper semver.Version, precision int) semver.Range {
+	if precision == 0 {
+		return func(semver.Version) bool { return true }
+	}
+	return func(v semver.Version) bool { return v.GTE(lower) && v.LT(upper) }
+}
@@ -0,0 +1,94 @@
+package versions_test
+
+import (
+	"github.com/blang/semver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet/v7"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/versions"
+)
+
+var _ = Describe("Constraint", func() {
+	Describe("ParseTolerant", func() {
+		It("parses PivNet style versions", func() {
+			v, err := versions.ParseTolerant("2.10.3-build.4")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(semver.Version{Major: 2, Minor: 10, Patch: 3}))
+
+			v, err = versions.ParseTolerant("Binary 1.0.11")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(semver.Version{Major: 1, Minor: 0, Patch: 11}))
+
+			v, err = versions.ParseTolerant("621.99")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(semver.Version{Major: 621, Minor: 99}))
+		})
+
+		Context("when the version contains no numbers", func() {
+			It("returns an error", func() {
+				_, err := versions.ParseTolerant("latest")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("cannot find a semver version in 'latest'"))
+			})
+		})
+	})
+
+	Describe("NewConstraint", func() {
+		Context("when the constraint is invalid", func() {
+			It("returns an error", func() {
+				for _, constraint := range []string{"", ">=two.ten", "2.10 ||", ">="} {
+					_, err := versions.NewConstraint(constraint)
+					Expect(err).To(HaveOccurred(), constraint)
+					Expect(err.Error()).To(ContainSubstring("invalid version constraint"))
+				}
+			})
+		})
+	})
+
+	Describe("Matches", func() {
+		DescribeTable("matching versions against constraints",
+			func(constraint string, version string, expected bool) {
+				c, err := versions.NewConstraint(constraint)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(c.Matches(version)).To(Equal(expected))
+			},
+			Entry("partial lower and upper bounds include", ">=2.10 <2.12", "2.11.4", true),
+			Entry("partial lower and upper bounds exclude the upper bound", ">=2.10 <2.12", "2.12.0", false),
+			Entry("partial lower and upper bounds exclude below", ">=2.10 <2.12", "2.9.15", false),
+			Entry("operators separated from versions", ">= 2.10 < 2.12", "2.10.0", true),
+			Entry("tilde with minor includes patches", "~2.10", "2.10.7", true),
+			Entry("tilde with minor excludes next minor", "~2.10", "2.11.0", false),
+			Entry("tilde with patch includes later patches", "~2.10.3", "2.10.9", true),
+			Entry("tilde with patch excludes earlier patches", "~2.10.3", "2.10.2", false),
+			Entry("caret includes later minors", "^2.10.3", "2.13.0", true),
+			Entry("caret excludes next major", "^2.10.3", "3.0.0", false),
+			Entry("caret below 1.0.0 excludes next minor", "^0.2.3", "0.3.0", false),
+			Entry("partial version matches its line", "2.10", "2.10.1", true),
+			Entry("wildcard matches its line", "2.x", "2.99.0", true),
+			Entry("greater than a partial version excludes its line", ">2.10", "2.10.9", false),
+			Entry("less than or equal to a partial version includes its line", "<=2.10", "2.10.9", true),
+			Entry("not equal excludes the version", "!=2.10.3", "2.10.3", false),
+			Entry("alternatives", "~2.10 || ~2.12", "2.12.1", true),
+			Entry("build suffixes are ignored", ">=2.10.3", "2.10.3-build.4", true),
+			Entry("prefixes are ignored", "~1.0", "Binary 1.0.11", true),
+			Entry("versions without numbers never match", ">=0", "latest", false),
+		)
+	})
+
+	Describe("Releases", func() {
+		It("returns the releases satisfying the constraint in their original order", func() {
+			c, err := versions.NewConstraint("~2.10 || ~2.12")
+			Expect(err).NotTo(HaveOccurred())
+
+			releases := []pivnet.Release{
+				{ID: 1, Version: "2.12.0"},
+				{ID: 2, Version: "2.11.3"},
+				{ID: 3, Version: "2.10.5-build.2"},
+			}
+
+			Expect(c.Releases(releases)).To(Equal([]pivnet.Release{releases[0], releases[2]}))
+		})
+	})
+})
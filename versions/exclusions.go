@@ -0,0 +1,94 @@
+package versions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// regexMarker : encloses a pattern to be treated as a regular expression even without other metacharacters
+	regexMarker = "/"
+	// regexMetacharacters : the metacharacters, other than '.', which make a pattern a regular expression
+	regexMetacharacters = `\^$*+?()[]{}|`
+)
+
+var plainVersionRegex = regexp.MustCompile(`^v?\d+(?:\.\d+)*$`)
+
+// Exclusion : matches versions by exact version, regular expression or semver range
+type Exclusion struct {
+	pattern    string
+	regex      *regexp.Regexp
+	constraint *Constraint
+}
+
+// NewExclusion : creates an Exclusion from a pattern which is an exact version, a semver range, a regular expression
+// when it contains metacharacters other than '.', or a regular expression enclosed in '/' such as '/621.9\d/'
+func NewExclusion(pattern string) (Exclusion, error) {
+	e := Exclusion{pattern: pattern}
+
+	if len(pattern) > 2*len(regexMarker) && strings.HasPrefix(pattern, regexMarker) && strings.HasSuffix(pattern, regexMarker) {
+		regex, err := compileWholeVersionRegex(strings.TrimSuffix(strings.TrimPrefix(pattern, regexMarker), regexMarker))
+		if err != nil {
+			return Exclusion{}, fmt.Errorf("'%s' is not a valid regular expression: %s", pattern, err)
+		}
+		e.regex = regex
+		return e, nil
+	}
+
+	// plain versions are only ever matched exactly, so '621.99' does not exclude '621.991' or '621.99.1'
+	if plainVersionRegex.MatchString(pattern) {
+		return e, nil
+	}
+
+	constraint, err := NewConstraint(pattern)
+	if err == nil {
+		e.constraint = &constraint
+		return e, nil
+	}
+
+	if strings.ContainsAny(pattern, regexMetacharacters) {
+		regex, err := compileWholeVersionRegex(pattern)
+		if err != nil {
+			return Exclusion{}, fmt.Errorf("'%s' is neither a valid regular expression nor a valid semver range", pattern)
+		}
+		e.regex = regex
+	}
+
+	return e, nil
+}
+
+// NewExclusions : creates an Exclusion for each of the patterns
+func NewExclusions(patterns []string) ([]Exclusion, error) {
+	exclusions := make([]Exclusion, len(patterns))
+	for i, pattern := range patterns {
+		e, err := NewExclusion(pattern)
+		if err != nil {
+			return nil, err
+		}
+		exclusions[i] = e
+	}
+	return exclusions, nil
+}
+
+// String : the pattern the Exclusion was created from
+func (e Exclusion) String() string {
+	return e.pattern
+}
+
+// Matches : whether the version is the exact version, matches the regular expression or falls within the semver range
+func (e Exclusion) Matches(version string) bool {
+	if version == e.pattern {
+		return true
+	}
+
+	if e.regex != nil && e.regex.MatchString(version) {
+		return true
+	}
+
+	return e.constraint != nil && e.constraint.Matches(version)
+}
+
+func compileWholeVersionRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(fmt.Sprintf("^(?:%s)$", pattern))
+}
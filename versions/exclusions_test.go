@@ -0,0 +1,109 @@
+package versions_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shanman190/pivnet-product-stemcell-resource/versions"
+)
+
+var _ = Describe("Exclusions", func() {
+	Describe("NewExclusion", func() {
+		Context("when the pattern is neither a regular expression nor a semver range", func() {
+			It("returns an error", func() {
+				_, err := versions.NewExclusion("1.2.(")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("'1.2.(' is neither a valid regular expression nor a valid semver range"))
+			})
+		})
+
+		Context("when the pattern is an invalid regular expression enclosed in '/'", func() {
+			It("returns an error", func() {
+				_, err := versions.NewExclusion("/621.[/")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("'/621.[/' is not a valid regular expression"))
+			})
+		})
+	})
+
+	Describe("Matches", func() {
+		It("matches exact versions", func() {
+			e, err := versions.NewExclusion("621.99")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(e.Matches("621.99")).To(BeTrue())
+			Expect(e.Matches("621.98")).To(BeFalse())
+			Expect(e.Matches("621.991")).To(BeFalse())
+		})
+
+		It("does not treat '.' in exact versions as a regular expression", func() {
+			e, err := versions.NewExclusion("621.99")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(e.Matches("621a99")).To(BeFalse())
+			Expect(e.Matches("621.99.1")).To(BeFalse())
+		})
+
+		It("matches versions which are neither semver nor regular expressions exactly", func() {
+			e, err := versions.NewExclusion("2.10.0-build.1")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(e.Matches("2.10.0-build.1")).To(BeTrue())
+			Expect(e.Matches("2.10.0-build-1")).To(BeFalse())
+			Expect(e.Matches("2.10.0")).To(BeFalse())
+		})
+
+		It("matches regular expressions against the whole version", func() {
+			e, err := versions.NewExclusion(`621\.9\d`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(e.Matches("621.95")).To(BeTrue())
+			Expect(e.Matches("621.9")).To(BeFalse())
+			Expect(e.Matches("1621.95")).To(BeFalse())
+		})
+
+		It("matches regular expressions enclosed in '/' against the whole version", func() {
+			e, err := versions.NewExclusion("/621.9./")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(e.Matches("621.95")).To(BeTrue())
+			Expect(e.Matches("621a9b")).To(BeTrue())
+			Expect(e.Matches("621.9")).To(BeFalse())
+		})
+
+		It("matches semver ranges", func() {
+			e, err := versions.NewExclusion(">=2.10.0 <2.10.5")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(e.Matches("2.10.3")).To(BeTrue())
+			Expect(e.Matches("2.10")).To(BeTrue())
+			Expect(e.Matches("2.10.5")).To(BeFalse())
+		})
+
+		It("matches semver ranges containing regular expression metacharacters as ranges", func() {
+			e, err := versions.NewExclusion("^2.10 || 3.*")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(e.Matches("2.10.3")).To(BeTrue())
+			Expect(e.Matches("3.0.1")).To(BeTrue())
+			Expect(e.Matches("2.9.0")).To(BeFalse())
+			Expect(e.Matches("4.0.0")).To(BeFalse())
+		})
+
+		It("parses the versions matched against semver ranges tolerantly", func() {
+			e, err := versions.NewExclusion("~1.0")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(e.Matches("Binary 1.0.11")).To(BeTrue())
+			Expect(e.Matches("1.0.11-build.4")).To(BeTrue())
+			Expect(e.Matches("1.1.0")).To(BeFalse())
+		})
+	})
+
+	Describe("NewExclusions", func() {
+		It("returns an error when any pattern is invalid", func() {
+			_, err := versions.NewExclusions([]string{"1.2.3", "[a-"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})